@@ -14,6 +14,7 @@ const PHASE_COLORS: Record<string, string> = {
   cruise: "#00e676",
   descent: "#ffca28",
   landing: "#ff5252",
+  holding: "#b388ff",
 };
 
 function getPhaseColor(phase: string): string {
@@ -13,6 +13,8 @@ const PHASE_COLOR_EXPR = [
   "#ffca28",
   ["==", ["get", "phase"], "landing"],
   "#ff5252",
+  ["==", ["get", "phase"], "holding"],
+  "#b388ff",
   "#607d8b",
 ] as mapboxgl.ExpressionSpecification;
 
@@ -3,6 +3,7 @@ const PHASES = [
   { name: "Climb", color: "#ffab40" },
   { name: "Cruise", color: "#00e676" },
   { name: "Descent", color: "#ffca28" },
+  { name: "Holding", color: "#b388ff" },
   { name: "Landing", color: "#ff5252" },
   { name: "Landed", color: "#607d8b" },
 ] as const;
@@ -7,4 +7,5 @@ export const FlightPhaseSchema = z.enum([
   "descent",
   "landing",
   "landed",
+  "holding",
 ]);
@@ -42,7 +42,15 @@ export const FlightRoutePropertiesSchema = z.union([
     to: z.string(),
     progress: z.number().optional(),
     phase: z
-      .enum(["takeoff", "climb", "cruise", "descent", "landing", "landed"])
+      .enum([
+        "takeoff",
+        "climb",
+        "cruise",
+        "descent",
+        "landing",
+        "landed",
+        "holding",
+      ])
       .optional(),
     selected: z.boolean().optional(),
   }),
@@ -54,7 +62,15 @@ export const FlightRoutePropertiesSchema = z.union([
     arrivalAirport: z.string(),
     progress: z.number().optional(),
     phase: z
-      .enum(["takeoff", "climb", "cruise", "descent", "landing", "landed"])
+      .enum([
+        "takeoff",
+        "climb",
+        "cruise",
+        "descent",
+        "landing",
+        "landed",
+        "holding",
+      ])
       .optional(),
     selected: z.boolean().optional(),
   }),
@@ -108,7 +124,14 @@ export function normalizeFlightRouteProperties(
   departureAirport: string;
   arrivalAirport: string;
   progress?: number;
-  phase?: "takeoff" | "climb" | "cruise" | "descent" | "landing" | "landed";
+  phase?:
+    | "takeoff"
+    | "climb"
+    | "cruise"
+    | "descent"
+    | "landing"
+    | "landed"
+    | "holding";
   selected?: boolean;
 } {
   if ("from" in properties && "to" in properties) {
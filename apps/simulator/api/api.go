@@ -0,0 +1,16 @@
+// Package api holds the published schemas for the simulator's wire formats.
+package api
+
+import _ "embed"
+
+//go:generate protoc -I . --go_out=. --go_opt=module=github.com/hannan/voyager/simulator/api --go-grpc_out=. --go-grpc_opt=module=github.com/hannan/voyager/simulator/api flights.proto flight_service.proto
+
+// FlightsProto describes the binary frames sent on /ws/flights.
+//
+//go:embed flights.proto
+var FlightsProto []byte
+
+// FlightServiceProto describes the gRPC service; it imports flights.proto.
+//
+//go:embed flight_service.proto
+var FlightServiceProto []byte
@@ -0,0 +1,787 @@
+// Typed access to the simulated traffic for backend services, served on the
+// gRPC port next to the HTTP API.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: flight_service.proto
+
+package flightsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Empty fields match everything; set fields must all match.
+type FlightFilter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Airline name as in Flight.airline.
+	Airline string `protobuf:"bytes,1,opt,name=airline,proto3" json:"airline,omitempty"`
+	// IATA code of either end of the flight.
+	Airport          string  `protobuf:"bytes,2,opt,name=airport,proto3" json:"airport,omitempty"`
+	DepartureAirport string  `protobuf:"bytes,3,opt,name=departure_airport,json=departureAirport,proto3" json:"departure_airport,omitempty"`
+	ArrivalAirport   string  `protobuf:"bytes,4,opt,name=arrival_airport,json=arrivalAirport,proto3" json:"arrival_airport,omitempty"`
+	AircraftType     string  `protobuf:"bytes,5,opt,name=aircraft_type,json=aircraftType,proto3" json:"aircraft_type,omitempty"`
+	Phases           []Phase `protobuf:"varint,6,rep,packed,name=phases,proto3,enum=voyager.flights.v1.Phase" json:"phases,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *FlightFilter) Reset() {
+	*x = FlightFilter{}
+	mi := &file_flight_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlightFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlightFilter) ProtoMessage() {}
+
+func (x *FlightFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlightFilter.ProtoReflect.Descriptor instead.
+func (*FlightFilter) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FlightFilter) GetAirline() string {
+	if x != nil {
+		return x.Airline
+	}
+	return ""
+}
+
+func (x *FlightFilter) GetAirport() string {
+	if x != nil {
+		return x.Airport
+	}
+	return ""
+}
+
+func (x *FlightFilter) GetDepartureAirport() string {
+	if x != nil {
+		return x.DepartureAirport
+	}
+	return ""
+}
+
+func (x *FlightFilter) GetArrivalAirport() string {
+	if x != nil {
+		return x.ArrivalAirport
+	}
+	return ""
+}
+
+func (x *FlightFilter) GetAircraftType() string {
+	if x != nil {
+		return x.AircraftType
+	}
+	return ""
+}
+
+func (x *FlightFilter) GetPhases() []Phase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+// Degrees; west greater than east crosses the antimeridian.
+type BoundingBox struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	West          float64                `protobuf:"fixed64,1,opt,name=west,proto3" json:"west,omitempty"`
+	South         float64                `protobuf:"fixed64,2,opt,name=south,proto3" json:"south,omitempty"`
+	East          float64                `protobuf:"fixed64,3,opt,name=east,proto3" json:"east,omitempty"`
+	North         float64                `protobuf:"fixed64,4,opt,name=north,proto3" json:"north,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BoundingBox) Reset() {
+	*x = BoundingBox{}
+	mi := &file_flight_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BoundingBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BoundingBox) ProtoMessage() {}
+
+func (x *BoundingBox) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BoundingBox.ProtoReflect.Descriptor instead.
+func (*BoundingBox) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BoundingBox) GetWest() float64 {
+	if x != nil {
+		return x.West
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetSouth() float64 {
+	if x != nil {
+		return x.South
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetEast() float64 {
+	if x != nil {
+		return x.East
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetNorth() float64 {
+	if x != nil {
+		return x.North
+	}
+	return 0
+}
+
+type StreamFlightsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *FlightFilter          `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Bbox          *BoundingBox           `protobuf:"bytes,2,opt,name=bbox,proto3" json:"bbox,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFlightsRequest) Reset() {
+	*x = StreamFlightsRequest{}
+	mi := &file_flight_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFlightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFlightsRequest) ProtoMessage() {}
+
+func (x *StreamFlightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFlightsRequest.ProtoReflect.Descriptor instead.
+func (*StreamFlightsRequest) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StreamFlightsRequest) GetFilter() *FlightFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *StreamFlightsRequest) GetBbox() *BoundingBox {
+	if x != nil {
+		return x.Bbox
+	}
+	return nil
+}
+
+type GetFlightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFlightRequest) Reset() {
+	*x = GetFlightRequest{}
+	mi := &file_flight_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFlightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFlightRequest) ProtoMessage() {}
+
+func (x *GetFlightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFlightRequest.ProtoReflect.Descriptor instead.
+func (*GetFlightRequest) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetFlightRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListFlightsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Filter *FlightFilter          `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Bbox   *BoundingBox           `protobuf:"bytes,2,opt,name=bbox,proto3" json:"bbox,omitempty"`
+	// Defaults to 100, at most 1000.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token from the previous response.
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlightsRequest) Reset() {
+	*x = ListFlightsRequest{}
+	mi := &file_flight_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlightsRequest) ProtoMessage() {}
+
+func (x *ListFlightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlightsRequest.ProtoReflect.Descriptor instead.
+func (*ListFlightsRequest) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListFlightsRequest) GetFilter() *FlightFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListFlightsRequest) GetBbox() *BoundingBox {
+	if x != nil {
+		return x.Bbox
+	}
+	return nil
+}
+
+func (x *ListFlightsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListFlightsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListFlightsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Flights []*Flight              `protobuf:"bytes,1,rep,name=flights,proto3" json:"flights,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlightsResponse) Reset() {
+	*x = ListFlightsResponse{}
+	mi := &file_flight_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlightsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlightsResponse) ProtoMessage() {}
+
+func (x *ListFlightsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlightsResponse.ProtoReflect.Descriptor instead.
+func (*ListFlightsResponse) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListFlightsResponse) GetFlights() []*Flight {
+	if x != nil {
+		return x.Flights
+	}
+	return nil
+}
+
+func (x *ListFlightsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetRouteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Points along the path; defaults to 128.
+	Points        int32 `protobuf:"varint,2,opt,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRouteRequest) Reset() {
+	*x = GetRouteRequest{}
+	mi := &file_flight_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRouteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRouteRequest) ProtoMessage() {}
+
+func (x *GetRouteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRouteRequest.ProtoReflect.Descriptor instead.
+func (*GetRouteRequest) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetRouteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetRouteRequest) GetPoints() int32 {
+	if x != nil {
+		return x.Points
+	}
+	return 0
+}
+
+type Route struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FlightId         string                 `protobuf:"bytes,1,opt,name=flight_id,json=flightId,proto3" json:"flight_id,omitempty"`
+	DepartureAirport string                 `protobuf:"bytes,2,opt,name=departure_airport,json=departureAirport,proto3" json:"departure_airport,omitempty"`
+	ArrivalAirport   string                 `protobuf:"bytes,3,opt,name=arrival_airport,json=arrivalAirport,proto3" json:"arrival_airport,omitempty"`
+	// Points along the legs, each leg a great circle between two fixes.
+	Path       []*Coordinate `protobuf:"bytes,4,rep,name=path,proto3" json:"path,omitempty"`
+	DistanceNm float64       `protobuf:"fixed64,5,opt,name=distance_nm,json=distanceNm,proto3" json:"distance_nm,omitempty"`
+	// From the departure airport to the arrival airport.
+	Fixes         []*RouteFix `protobuf:"bytes,6,rep,name=fixes,proto3" json:"fixes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Route) Reset() {
+	*x = Route{}
+	mi := &file_flight_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Route) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Route) ProtoMessage() {}
+
+func (x *Route) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Route.ProtoReflect.Descriptor instead.
+func (*Route) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Route) GetFlightId() string {
+	if x != nil {
+		return x.FlightId
+	}
+	return ""
+}
+
+func (x *Route) GetDepartureAirport() string {
+	if x != nil {
+		return x.DepartureAirport
+	}
+	return ""
+}
+
+func (x *Route) GetArrivalAirport() string {
+	if x != nil {
+		return x.ArrivalAirport
+	}
+	return ""
+}
+
+func (x *Route) GetPath() []*Coordinate {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+func (x *Route) GetDistanceNm() float64 {
+	if x != nil {
+		return x.DistanceNm
+	}
+	return 0
+}
+
+func (x *Route) GetFixes() []*RouteFix {
+	if x != nil {
+		return x.Fixes
+	}
+	return nil
+}
+
+type RouteFix struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Fix identifier, or the airport code at either end.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The airway flown to reach this fix; empty on a direct leg.
+	Airway        string      `protobuf:"bytes,2,opt,name=airway,proto3" json:"airway,omitempty"`
+	Position      *Coordinate `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteFix) Reset() {
+	*x = RouteFix{}
+	mi := &file_flight_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteFix) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteFix) ProtoMessage() {}
+
+func (x *RouteFix) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteFix.ProtoReflect.Descriptor instead.
+func (*RouteFix) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RouteFix) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RouteFix) GetAirway() string {
+	if x != nil {
+		return x.Airway
+	}
+	return ""
+}
+
+func (x *RouteFix) GetPosition() *Coordinate {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+type Coordinate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Longitude     float64                `protobuf:"fixed64,1,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Latitude      float64                `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Coordinate) Reset() {
+	*x = Coordinate{}
+	mi := &file_flight_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coordinate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coordinate) ProtoMessage() {}
+
+func (x *Coordinate) ProtoReflect() protoreflect.Message {
+	mi := &file_flight_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coordinate.ProtoReflect.Descriptor instead.
+func (*Coordinate) Descriptor() ([]byte, []int) {
+	return file_flight_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Coordinate) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Coordinate) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+var File_flight_service_proto protoreflect.FileDescriptor
+
+const file_flight_service_proto_rawDesc = "" +
+	"\n" +
+	"\x14flight_service.proto\x12\x12voyager.flights.v1\x1a\rflights.proto\"\xf0\x01\n" +
+	"\fFlightFilter\x12\x18\n" +
+	"\aairline\x18\x01 \x01(\tR\aairline\x12\x18\n" +
+	"\aairport\x18\x02 \x01(\tR\aairport\x12+\n" +
+	"\x11departure_airport\x18\x03 \x01(\tR\x10departureAirport\x12'\n" +
+	"\x0farrival_airport\x18\x04 \x01(\tR\x0earrivalAirport\x12#\n" +
+	"\raircraft_type\x18\x05 \x01(\tR\faircraftType\x121\n" +
+	"\x06phases\x18\x06 \x03(\x0e2\x19.voyager.flights.v1.PhaseR\x06phases\"a\n" +
+	"\vBoundingBox\x12\x12\n" +
+	"\x04west\x18\x01 \x01(\x01R\x04west\x12\x14\n" +
+	"\x05south\x18\x02 \x01(\x01R\x05south\x12\x12\n" +
+	"\x04east\x18\x03 \x01(\x01R\x04east\x12\x14\n" +
+	"\x05north\x18\x04 \x01(\x01R\x05north\"\x85\x01\n" +
+	"\x14StreamFlightsRequest\x128\n" +
+	"\x06filter\x18\x01 \x01(\v2 .voyager.flights.v1.FlightFilterR\x06filter\x123\n" +
+	"\x04bbox\x18\x02 \x01(\v2\x1f.voyager.flights.v1.BoundingBoxR\x04bbox\"\"\n" +
+	"\x10GetFlightRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xbf\x01\n" +
+	"\x12ListFlightsRequest\x128\n" +
+	"\x06filter\x18\x01 \x01(\v2 .voyager.flights.v1.FlightFilterR\x06filter\x123\n" +
+	"\x04bbox\x18\x02 \x01(\v2\x1f.voyager.flights.v1.BoundingBoxR\x04bbox\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"s\n" +
+	"\x13ListFlightsResponse\x124\n" +
+	"\aflights\x18\x01 \x03(\v2\x1a.voyager.flights.v1.FlightR\aflights\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"9\n" +
+	"\x0fGetRouteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06points\x18\x02 \x01(\x05R\x06points\"\x83\x02\n" +
+	"\x05Route\x12\x1b\n" +
+	"\tflight_id\x18\x01 \x01(\tR\bflightId\x12+\n" +
+	"\x11departure_airport\x18\x02 \x01(\tR\x10departureAirport\x12'\n" +
+	"\x0farrival_airport\x18\x03 \x01(\tR\x0earrivalAirport\x122\n" +
+	"\x04path\x18\x04 \x03(\v2\x1e.voyager.flights.v1.CoordinateR\x04path\x12\x1f\n" +
+	"\vdistance_nm\x18\x05 \x01(\x01R\n" +
+	"distanceNm\x122\n" +
+	"\x05fixes\x18\x06 \x03(\v2\x1c.voyager.flights.v1.RouteFixR\x05fixes\"r\n" +
+	"\bRouteFix\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06airway\x18\x02 \x01(\tR\x06airway\x12:\n" +
+	"\bposition\x18\x03 \x01(\v2\x1e.voyager.flights.v1.CoordinateR\bposition\"F\n" +
+	"\n" +
+	"Coordinate\x12\x1c\n" +
+	"\tlongitude\x18\x01 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\blatitude\x18\x02 \x01(\x01R\blatitude2\xe9\x02\n" +
+	"\rFlightService\x12]\n" +
+	"\rStreamFlights\x12(.voyager.flights.v1.StreamFlightsRequest\x1a .voyager.flights.v1.FlightsFrame0\x01\x12M\n" +
+	"\tGetFlight\x12$.voyager.flights.v1.GetFlightRequest\x1a\x1a.voyager.flights.v1.Flight\x12^\n" +
+	"\vListFlights\x12&.voyager.flights.v1.ListFlightsRequest\x1a'.voyager.flights.v1.ListFlightsResponse\x12J\n" +
+	"\bGetRoute\x12#.voyager.flights.v1.GetRouteRequest\x1a\x19.voyager.flights.v1.RouteB3Z1github.com/hannan/voyager/simulator/api/flightsv1b\x06proto3"
+
+var (
+	file_flight_service_proto_rawDescOnce sync.Once
+	file_flight_service_proto_rawDescData []byte
+)
+
+func file_flight_service_proto_rawDescGZIP() []byte {
+	file_flight_service_proto_rawDescOnce.Do(func() {
+		file_flight_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_flight_service_proto_rawDesc), len(file_flight_service_proto_rawDesc)))
+	})
+	return file_flight_service_proto_rawDescData
+}
+
+var file_flight_service_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_flight_service_proto_goTypes = []any{
+	(*FlightFilter)(nil),         // 0: voyager.flights.v1.FlightFilter
+	(*BoundingBox)(nil),          // 1: voyager.flights.v1.BoundingBox
+	(*StreamFlightsRequest)(nil), // 2: voyager.flights.v1.StreamFlightsRequest
+	(*GetFlightRequest)(nil),     // 3: voyager.flights.v1.GetFlightRequest
+	(*ListFlightsRequest)(nil),   // 4: voyager.flights.v1.ListFlightsRequest
+	(*ListFlightsResponse)(nil),  // 5: voyager.flights.v1.ListFlightsResponse
+	(*GetRouteRequest)(nil),      // 6: voyager.flights.v1.GetRouteRequest
+	(*Route)(nil),                // 7: voyager.flights.v1.Route
+	(*RouteFix)(nil),             // 8: voyager.flights.v1.RouteFix
+	(*Coordinate)(nil),           // 9: voyager.flights.v1.Coordinate
+	(Phase)(0),                   // 10: voyager.flights.v1.Phase
+	(*Flight)(nil),               // 11: voyager.flights.v1.Flight
+	(*FlightsFrame)(nil),         // 12: voyager.flights.v1.FlightsFrame
+}
+var file_flight_service_proto_depIdxs = []int32{
+	10, // 0: voyager.flights.v1.FlightFilter.phases:type_name -> voyager.flights.v1.Phase
+	0,  // 1: voyager.flights.v1.StreamFlightsRequest.filter:type_name -> voyager.flights.v1.FlightFilter
+	1,  // 2: voyager.flights.v1.StreamFlightsRequest.bbox:type_name -> voyager.flights.v1.BoundingBox
+	0,  // 3: voyager.flights.v1.ListFlightsRequest.filter:type_name -> voyager.flights.v1.FlightFilter
+	1,  // 4: voyager.flights.v1.ListFlightsRequest.bbox:type_name -> voyager.flights.v1.BoundingBox
+	11, // 5: voyager.flights.v1.ListFlightsResponse.flights:type_name -> voyager.flights.v1.Flight
+	9,  // 6: voyager.flights.v1.Route.path:type_name -> voyager.flights.v1.Coordinate
+	8,  // 7: voyager.flights.v1.Route.fixes:type_name -> voyager.flights.v1.RouteFix
+	9,  // 8: voyager.flights.v1.RouteFix.position:type_name -> voyager.flights.v1.Coordinate
+	2,  // 9: voyager.flights.v1.FlightService.StreamFlights:input_type -> voyager.flights.v1.StreamFlightsRequest
+	3,  // 10: voyager.flights.v1.FlightService.GetFlight:input_type -> voyager.flights.v1.GetFlightRequest
+	4,  // 11: voyager.flights.v1.FlightService.ListFlights:input_type -> voyager.flights.v1.ListFlightsRequest
+	6,  // 12: voyager.flights.v1.FlightService.GetRoute:input_type -> voyager.flights.v1.GetRouteRequest
+	12, // 13: voyager.flights.v1.FlightService.StreamFlights:output_type -> voyager.flights.v1.FlightsFrame
+	11, // 14: voyager.flights.v1.FlightService.GetFlight:output_type -> voyager.flights.v1.Flight
+	5,  // 15: voyager.flights.v1.FlightService.ListFlights:output_type -> voyager.flights.v1.ListFlightsResponse
+	7,  // 16: voyager.flights.v1.FlightService.GetRoute:output_type -> voyager.flights.v1.Route
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_flight_service_proto_init() }
+func file_flight_service_proto_init() {
+	if File_flight_service_proto != nil {
+		return
+	}
+	file_flights_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_flight_service_proto_rawDesc), len(file_flight_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_flight_service_proto_goTypes,
+		DependencyIndexes: file_flight_service_proto_depIdxs,
+		MessageInfos:      file_flight_service_proto_msgTypes,
+	}.Build()
+	File_flight_service_proto = out.File
+	file_flight_service_proto_goTypes = nil
+	file_flight_service_proto_depIdxs = nil
+}
@@ -0,0 +1,252 @@
+// Typed access to the simulated traffic for backend services, served on the
+// gRPC port next to the HTTP API.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: flight_service.proto
+
+package flightsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FlightService_StreamFlights_FullMethodName = "/voyager.flights.v1.FlightService/StreamFlights"
+	FlightService_GetFlight_FullMethodName     = "/voyager.flights.v1.FlightService/GetFlight"
+	FlightService_ListFlights_FullMethodName   = "/voyager.flights.v1.FlightService/ListFlights"
+	FlightService_GetRoute_FullMethodName      = "/voyager.flights.v1.FlightService/GetRoute"
+)
+
+// FlightServiceClient is the client API for FlightService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FlightServiceClient interface {
+	// Sends a frame on every broadcast, holding only the flights that match
+	// the request. A slow reader skips frames rather than falling behind.
+	StreamFlights(ctx context.Context, in *StreamFlightsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FlightsFrame], error)
+	GetFlight(ctx context.Context, in *GetFlightRequest, opts ...grpc.CallOption) (*Flight, error)
+	// Pages through the current flights in ID order.
+	ListFlights(ctx context.Context, in *ListFlightsRequest, opts ...grpc.CallOption) (*ListFlightsResponse, error)
+	// The flight's planned path: the legs between its route fixes, following
+	// airways where the simulator has an airway network.
+	GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*Route, error)
+}
+
+type flightServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFlightServiceClient(cc grpc.ClientConnInterface) FlightServiceClient {
+	return &flightServiceClient{cc}
+}
+
+func (c *flightServiceClient) StreamFlights(ctx context.Context, in *StreamFlightsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FlightsFrame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FlightService_ServiceDesc.Streams[0], FlightService_StreamFlights_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamFlightsRequest, FlightsFrame]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FlightService_StreamFlightsClient = grpc.ServerStreamingClient[FlightsFrame]
+
+func (c *flightServiceClient) GetFlight(ctx context.Context, in *GetFlightRequest, opts ...grpc.CallOption) (*Flight, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Flight)
+	err := c.cc.Invoke(ctx, FlightService_GetFlight_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flightServiceClient) ListFlights(ctx context.Context, in *ListFlightsRequest, opts ...grpc.CallOption) (*ListFlightsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFlightsResponse)
+	err := c.cc.Invoke(ctx, FlightService_ListFlights_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flightServiceClient) GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*Route, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Route)
+	err := c.cc.Invoke(ctx, FlightService_GetRoute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FlightServiceServer is the server API for FlightService service.
+// All implementations must embed UnimplementedFlightServiceServer
+// for forward compatibility.
+type FlightServiceServer interface {
+	// Sends a frame on every broadcast, holding only the flights that match
+	// the request. A slow reader skips frames rather than falling behind.
+	StreamFlights(*StreamFlightsRequest, grpc.ServerStreamingServer[FlightsFrame]) error
+	GetFlight(context.Context, *GetFlightRequest) (*Flight, error)
+	// Pages through the current flights in ID order.
+	ListFlights(context.Context, *ListFlightsRequest) (*ListFlightsResponse, error)
+	// The flight's planned path: the legs between its route fixes, following
+	// airways where the simulator has an airway network.
+	GetRoute(context.Context, *GetRouteRequest) (*Route, error)
+	mustEmbedUnimplementedFlightServiceServer()
+}
+
+// UnimplementedFlightServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFlightServiceServer struct{}
+
+func (UnimplementedFlightServiceServer) StreamFlights(*StreamFlightsRequest, grpc.ServerStreamingServer[FlightsFrame]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFlights not implemented")
+}
+func (UnimplementedFlightServiceServer) GetFlight(context.Context, *GetFlightRequest) (*Flight, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlight not implemented")
+}
+func (UnimplementedFlightServiceServer) ListFlights(context.Context, *ListFlightsRequest) (*ListFlightsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFlights not implemented")
+}
+func (UnimplementedFlightServiceServer) GetRoute(context.Context, *GetRouteRequest) (*Route, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoute not implemented")
+}
+func (UnimplementedFlightServiceServer) mustEmbedUnimplementedFlightServiceServer() {}
+func (UnimplementedFlightServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeFlightServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FlightServiceServer will
+// result in compilation errors.
+type UnsafeFlightServiceServer interface {
+	mustEmbedUnimplementedFlightServiceServer()
+}
+
+func RegisterFlightServiceServer(s grpc.ServiceRegistrar, srv FlightServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFlightServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FlightService_ServiceDesc, srv)
+}
+
+func _FlightService_StreamFlights_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamFlightsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FlightServiceServer).StreamFlights(m, &grpc.GenericServerStream[StreamFlightsRequest, FlightsFrame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FlightService_StreamFlightsServer = grpc.ServerStreamingServer[FlightsFrame]
+
+func _FlightService_GetFlight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlightServiceServer).GetFlight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlightService_GetFlight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlightServiceServer).GetFlight(ctx, req.(*GetFlightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlightService_ListFlights_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFlightsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlightServiceServer).ListFlights(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlightService_ListFlights_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlightServiceServer).ListFlights(ctx, req.(*ListFlightsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlightService_GetRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlightServiceServer).GetRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlightService_GetRoute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlightServiceServer).GetRoute(ctx, req.(*GetRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FlightService_ServiceDesc is the grpc.ServiceDesc for FlightService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FlightService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "voyager.flights.v1.FlightService",
+	HandlerType: (*FlightServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetFlight",
+			Handler:    _FlightService_GetFlight_Handler,
+		},
+		{
+			MethodName: "ListFlights",
+			Handler:    _FlightService_ListFlights_Handler,
+		},
+		{
+			MethodName: "GetRoute",
+			Handler:    _FlightService_GetRoute_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFlights",
+			Handler:       _FlightService_StreamFlights_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "flight_service.proto",
+}
@@ -0,0 +1,515 @@
+// Binary flight stream served on /ws/flights when the client requests the
+// "voyager.flights.v1+proto" WebSocket subprotocol. Every binary message is
+// one FlightsFrame carrying the full set of flights, like flights_geojson.
+//
+// Numbers are quantized integers; divide by the factor in the field name
+// (e5 = 1e5, e4 = 1e4, d10 = 10) to get the GeoJSON value back. Times are
+// unix seconds unless the field says otherwise.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: flights.proto
+
+package flightsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Phase int32
+
+const (
+	Phase_PHASE_UNSPECIFIED Phase = 0
+	Phase_PHASE_TAKEOFF     Phase = 1
+	Phase_PHASE_CLIMB       Phase = 2
+	Phase_PHASE_CRUISE      Phase = 3
+	Phase_PHASE_DESCENT     Phase = 4
+	Phase_PHASE_LANDING     Phase = 5
+	Phase_PHASE_LANDED      Phase = 6
+	Phase_PHASE_HOLDING     Phase = 7
+)
+
+// Enum value maps for Phase.
+var (
+	Phase_name = map[int32]string{
+		0: "PHASE_UNSPECIFIED",
+		1: "PHASE_TAKEOFF",
+		2: "PHASE_CLIMB",
+		3: "PHASE_CRUISE",
+		4: "PHASE_DESCENT",
+		5: "PHASE_LANDING",
+		6: "PHASE_LANDED",
+		7: "PHASE_HOLDING",
+	}
+	Phase_value = map[string]int32{
+		"PHASE_UNSPECIFIED": 0,
+		"PHASE_TAKEOFF":     1,
+		"PHASE_CLIMB":       2,
+		"PHASE_CRUISE":      3,
+		"PHASE_DESCENT":     4,
+		"PHASE_LANDING":     5,
+		"PHASE_LANDED":      6,
+		"PHASE_HOLDING":     7,
+	}
+)
+
+func (x Phase) Enum() *Phase {
+	p := new(Phase)
+	*p = x
+	return p
+}
+
+func (x Phase) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Phase) Descriptor() protoreflect.EnumDescriptor {
+	return file_flights_proto_enumTypes[0].Descriptor()
+}
+
+func (Phase) Type() protoreflect.EnumType {
+	return &file_flights_proto_enumTypes[0]
+}
+
+func (x Phase) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Phase.Descriptor instead.
+func (Phase) EnumDescriptor() ([]byte, []int) {
+	return file_flights_proto_rawDescGZIP(), []int{0}
+}
+
+type FlightsFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Same seq as the JSON stream; 0 on the frame sent at connect.
+	Seq               int64     `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	ServerTimestampMs int64     `protobuf:"varint,2,opt,name=server_timestamp_ms,json=serverTimestampMs,proto3" json:"server_timestamp_ms,omitempty"`
+	SimTimeMs         int64     `protobuf:"varint,3,opt,name=sim_time_ms,json=simTimeMs,proto3" json:"sim_time_ms,omitempty"`
+	TimeScale         float64   `protobuf:"fixed64,4,opt,name=time_scale,json=timeScale,proto3" json:"time_scale,omitempty"`
+	Flights           []*Flight `protobuf:"bytes,5,rep,name=flights,proto3" json:"flights,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FlightsFrame) Reset() {
+	*x = FlightsFrame{}
+	mi := &file_flights_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlightsFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlightsFrame) ProtoMessage() {}
+
+func (x *FlightsFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_flights_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlightsFrame.ProtoReflect.Descriptor instead.
+func (*FlightsFrame) Descriptor() ([]byte, []int) {
+	return file_flights_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FlightsFrame) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *FlightsFrame) GetServerTimestampMs() int64 {
+	if x != nil {
+		return x.ServerTimestampMs
+	}
+	return 0
+}
+
+func (x *FlightsFrame) GetSimTimeMs() int64 {
+	if x != nil {
+		return x.SimTimeMs
+	}
+	return 0
+}
+
+func (x *FlightsFrame) GetTimeScale() float64 {
+	if x != nil {
+		return x.TimeScale
+	}
+	return 0
+}
+
+func (x *FlightsFrame) GetFlights() []*Flight {
+	if x != nil {
+		return x.Flights
+	}
+	return nil
+}
+
+type Flight struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CallSign         string                 `protobuf:"bytes,2,opt,name=call_sign,json=callSign,proto3" json:"call_sign,omitempty"`
+	Airline          string                 `protobuf:"bytes,3,opt,name=airline,proto3" json:"airline,omitempty"`
+	AircraftType     string                 `protobuf:"bytes,4,opt,name=aircraft_type,json=aircraftType,proto3" json:"aircraft_type,omitempty"`
+	AircraftCategory string                 `protobuf:"bytes,5,opt,name=aircraft_category,json=aircraftCategory,proto3" json:"aircraft_category,omitempty"`
+	DepartureAirport string                 `protobuf:"bytes,6,opt,name=departure_airport,json=departureAirport,proto3" json:"departure_airport,omitempty"`
+	ArrivalAirport   string                 `protobuf:"bytes,7,opt,name=arrival_airport,json=arrivalAirport,proto3" json:"arrival_airport,omitempty"`
+	Phase            Phase                  `protobuf:"varint,8,opt,name=phase,proto3,enum=voyager.flights.v1.Phase" json:"phase,omitempty"`
+	// Degrees times 1e5, about a metre.
+	LongitudeE5 int32 `protobuf:"zigzag32,9,opt,name=longitude_e5,json=longitudeE5,proto3" json:"longitude_e5,omitempty"`
+	LatitudeE5  int32 `protobuf:"zigzag32,10,opt,name=latitude_e5,json=latitudeE5,proto3" json:"latitude_e5,omitempty"`
+	// Feet.
+	Altitude int32 `protobuf:"zigzag32,11,opt,name=altitude,proto3" json:"altitude,omitempty"`
+	// Degrees times 10.
+	BearingD10 uint32 `protobuf:"varint,12,opt,name=bearing_d10,json=bearingD10,proto3" json:"bearing_d10,omitempty"`
+	// Knots, time-compressed like the JSON speed.
+	Speed uint32 `protobuf:"varint,13,opt,name=speed,proto3" json:"speed,omitempty"`
+	// Feet per minute.
+	VerticalSpeed  int32 `protobuf:"zigzag32,14,opt,name=vertical_speed,json=verticalSpeed,proto3" json:"vertical_speed,omitempty"`
+	CruiseAltitude int32 `protobuf:"zigzag32,15,opt,name=cruise_altitude,json=cruiseAltitude,proto3" json:"cruise_altitude,omitempty"`
+	// 0 to 10000.
+	ProgressE4 uint32 `protobuf:"varint,16,opt,name=progress_e4,json=progressE4,proto3" json:"progress_e4,omitempty"`
+	// Nautical miles times 10.
+	DistanceRemainingD10 uint32 `protobuf:"varint,17,opt,name=distance_remaining_d10,json=distanceRemainingD10,proto3" json:"distance_remaining_d10,omitempty"`
+	ScheduledDeparture   int64  `protobuf:"varint,18,opt,name=scheduled_departure,json=scheduledDeparture,proto3" json:"scheduled_departure,omitempty"`
+	ScheduledArrival     int64  `protobuf:"varint,19,opt,name=scheduled_arrival,json=scheduledArrival,proto3" json:"scheduled_arrival,omitempty"`
+	EstimatedArrival     int64  `protobuf:"varint,20,opt,name=estimated_arrival,json=estimatedArrival,proto3" json:"estimated_arrival,omitempty"`
+	LastComputedAt       int64  `protobuf:"varint,21,opt,name=last_computed_at,json=lastComputedAt,proto3" json:"last_computed_at,omitempty"`
+	// 16 raw bytes; the JSON traceID is their hex encoding.
+	TraceId []byte `protobuf:"bytes,22,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	// Degrees times 10; bearing_d10 is the track over the ground.
+	HeadingD10 uint32 `protobuf:"varint,23,opt,name=heading_d10,json=headingD10,proto3" json:"heading_d10,omitempty"`
+	// Knots, time-compressed like speed, which is the true airspeed.
+	GroundSpeed   uint32 `protobuf:"varint,24,opt,name=ground_speed,json=groundSpeed,proto3" json:"ground_speed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Flight) Reset() {
+	*x = Flight{}
+	mi := &file_flights_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Flight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Flight) ProtoMessage() {}
+
+func (x *Flight) ProtoReflect() protoreflect.Message {
+	mi := &file_flights_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Flight.ProtoReflect.Descriptor instead.
+func (*Flight) Descriptor() ([]byte, []int) {
+	return file_flights_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Flight) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Flight) GetCallSign() string {
+	if x != nil {
+		return x.CallSign
+	}
+	return ""
+}
+
+func (x *Flight) GetAirline() string {
+	if x != nil {
+		return x.Airline
+	}
+	return ""
+}
+
+func (x *Flight) GetAircraftType() string {
+	if x != nil {
+		return x.AircraftType
+	}
+	return ""
+}
+
+func (x *Flight) GetAircraftCategory() string {
+	if x != nil {
+		return x.AircraftCategory
+	}
+	return ""
+}
+
+func (x *Flight) GetDepartureAirport() string {
+	if x != nil {
+		return x.DepartureAirport
+	}
+	return ""
+}
+
+func (x *Flight) GetArrivalAirport() string {
+	if x != nil {
+		return x.ArrivalAirport
+	}
+	return ""
+}
+
+func (x *Flight) GetPhase() Phase {
+	if x != nil {
+		return x.Phase
+	}
+	return Phase_PHASE_UNSPECIFIED
+}
+
+func (x *Flight) GetLongitudeE5() int32 {
+	if x != nil {
+		return x.LongitudeE5
+	}
+	return 0
+}
+
+func (x *Flight) GetLatitudeE5() int32 {
+	if x != nil {
+		return x.LatitudeE5
+	}
+	return 0
+}
+
+func (x *Flight) GetAltitude() int32 {
+	if x != nil {
+		return x.Altitude
+	}
+	return 0
+}
+
+func (x *Flight) GetBearingD10() uint32 {
+	if x != nil {
+		return x.BearingD10
+	}
+	return 0
+}
+
+func (x *Flight) GetSpeed() uint32 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *Flight) GetVerticalSpeed() int32 {
+	if x != nil {
+		return x.VerticalSpeed
+	}
+	return 0
+}
+
+func (x *Flight) GetCruiseAltitude() int32 {
+	if x != nil {
+		return x.CruiseAltitude
+	}
+	return 0
+}
+
+func (x *Flight) GetProgressE4() uint32 {
+	if x != nil {
+		return x.ProgressE4
+	}
+	return 0
+}
+
+func (x *Flight) GetDistanceRemainingD10() uint32 {
+	if x != nil {
+		return x.DistanceRemainingD10
+	}
+	return 0
+}
+
+func (x *Flight) GetScheduledDeparture() int64 {
+	if x != nil {
+		return x.ScheduledDeparture
+	}
+	return 0
+}
+
+func (x *Flight) GetScheduledArrival() int64 {
+	if x != nil {
+		return x.ScheduledArrival
+	}
+	return 0
+}
+
+func (x *Flight) GetEstimatedArrival() int64 {
+	if x != nil {
+		return x.EstimatedArrival
+	}
+	return 0
+}
+
+func (x *Flight) GetLastComputedAt() int64 {
+	if x != nil {
+		return x.LastComputedAt
+	}
+	return 0
+}
+
+func (x *Flight) GetTraceId() []byte {
+	if x != nil {
+		return x.TraceId
+	}
+	return nil
+}
+
+func (x *Flight) GetHeadingD10() uint32 {
+	if x != nil {
+		return x.HeadingD10
+	}
+	return 0
+}
+
+func (x *Flight) GetGroundSpeed() uint32 {
+	if x != nil {
+		return x.GroundSpeed
+	}
+	return 0
+}
+
+var File_flights_proto protoreflect.FileDescriptor
+
+const file_flights_proto_rawDesc = "" +
+	"\n" +
+	"\rflights.proto\x12\x12voyager.flights.v1\"\xc5\x01\n" +
+	"\fFlightsFrame\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12.\n" +
+	"\x13server_timestamp_ms\x18\x02 \x01(\x03R\x11serverTimestampMs\x12\x1e\n" +
+	"\vsim_time_ms\x18\x03 \x01(\x03R\tsimTimeMs\x12\x1d\n" +
+	"\n" +
+	"time_scale\x18\x04 \x01(\x01R\ttimeScale\x124\n" +
+	"\aflights\x18\x05 \x03(\v2\x1a.voyager.flights.v1.FlightR\aflights\"\xfa\x06\n" +
+	"\x06Flight\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tcall_sign\x18\x02 \x01(\tR\bcallSign\x12\x18\n" +
+	"\aairline\x18\x03 \x01(\tR\aairline\x12#\n" +
+	"\raircraft_type\x18\x04 \x01(\tR\faircraftType\x12+\n" +
+	"\x11aircraft_category\x18\x05 \x01(\tR\x10aircraftCategory\x12+\n" +
+	"\x11departure_airport\x18\x06 \x01(\tR\x10departureAirport\x12'\n" +
+	"\x0farrival_airport\x18\a \x01(\tR\x0earrivalAirport\x12/\n" +
+	"\x05phase\x18\b \x01(\x0e2\x19.voyager.flights.v1.PhaseR\x05phase\x12!\n" +
+	"\flongitude_e5\x18\t \x01(\x11R\vlongitudeE5\x12\x1f\n" +
+	"\vlatitude_e5\x18\n" +
+	" \x01(\x11R\n" +
+	"latitudeE5\x12\x1a\n" +
+	"\baltitude\x18\v \x01(\x11R\baltitude\x12\x1f\n" +
+	"\vbearing_d10\x18\f \x01(\rR\n" +
+	"bearingD10\x12\x14\n" +
+	"\x05speed\x18\r \x01(\rR\x05speed\x12%\n" +
+	"\x0evertical_speed\x18\x0e \x01(\x11R\rverticalSpeed\x12'\n" +
+	"\x0fcruise_altitude\x18\x0f \x01(\x11R\x0ecruiseAltitude\x12\x1f\n" +
+	"\vprogress_e4\x18\x10 \x01(\rR\n" +
+	"progressE4\x124\n" +
+	"\x16distance_remaining_d10\x18\x11 \x01(\rR\x14distanceRemainingD10\x12/\n" +
+	"\x13scheduled_departure\x18\x12 \x01(\x03R\x12scheduledDeparture\x12+\n" +
+	"\x11scheduled_arrival\x18\x13 \x01(\x03R\x10scheduledArrival\x12+\n" +
+	"\x11estimated_arrival\x18\x14 \x01(\x03R\x10estimatedArrival\x12(\n" +
+	"\x10last_computed_at\x18\x15 \x01(\x03R\x0elastComputedAt\x12\x19\n" +
+	"\btrace_id\x18\x16 \x01(\fR\atraceId\x12\x1f\n" +
+	"\vheading_d10\x18\x17 \x01(\rR\n" +
+	"headingD10\x12!\n" +
+	"\fground_speed\x18\x18 \x01(\rR\vgroundSpeed*\x9f\x01\n" +
+	"\x05Phase\x12\x15\n" +
+	"\x11PHASE_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rPHASE_TAKEOFF\x10\x01\x12\x0f\n" +
+	"\vPHASE_CLIMB\x10\x02\x12\x10\n" +
+	"\fPHASE_CRUISE\x10\x03\x12\x11\n" +
+	"\rPHASE_DESCENT\x10\x04\x12\x11\n" +
+	"\rPHASE_LANDING\x10\x05\x12\x10\n" +
+	"\fPHASE_LANDED\x10\x06\x12\x11\n" +
+	"\rPHASE_HOLDING\x10\aB3Z1github.com/hannan/voyager/simulator/api/flightsv1b\x06proto3"
+
+var (
+	file_flights_proto_rawDescOnce sync.Once
+	file_flights_proto_rawDescData []byte
+)
+
+func file_flights_proto_rawDescGZIP() []byte {
+	file_flights_proto_rawDescOnce.Do(func() {
+		file_flights_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_flights_proto_rawDesc), len(file_flights_proto_rawDesc)))
+	})
+	return file_flights_proto_rawDescData
+}
+
+var file_flights_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_flights_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_flights_proto_goTypes = []any{
+	(Phase)(0),           // 0: voyager.flights.v1.Phase
+	(*FlightsFrame)(nil), // 1: voyager.flights.v1.FlightsFrame
+	(*Flight)(nil),       // 2: voyager.flights.v1.Flight
+}
+var file_flights_proto_depIdxs = []int32{
+	2, // 0: voyager.flights.v1.FlightsFrame.flights:type_name -> voyager.flights.v1.Flight
+	0, // 1: voyager.flights.v1.Flight.phase:type_name -> voyager.flights.v1.Phase
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_flights_proto_init() }
+func file_flights_proto_init() {
+	if File_flights_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_flights_proto_rawDesc), len(file_flights_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_flights_proto_goTypes,
+		DependencyIndexes: file_flights_proto_depIdxs,
+		EnumInfos:         file_flights_proto_enumTypes,
+		MessageInfos:      file_flights_proto_msgTypes,
+	}.Build()
+	File_flights_proto = out.File
+	file_flights_proto_goTypes = nil
+	file_flights_proto_depIdxs = nil
+}
@@ -2,37 +2,163 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/hannan/voyager/simulator/internal/data"
+	"github.com/hannan/voyager/simulator/internal/nav"
+	"github.com/hannan/voyager/simulator/internal/recording"
 	"github.com/hannan/voyager/simulator/internal/simulator"
 	"github.com/hannan/voyager/simulator/internal/telemetry"
+	"github.com/hannan/voyager/simulator/internal/wind"
 )
 
 func main() {
+	seed := flag.Uint64("seed", 0, "seed for a deterministic simulation (random, on the wall clock, when unset)")
+	start := flag.String("start", "2025-01-01T00:00:00Z", "simulated start time (RFC3339) when -seed is set")
+	timeScale := flag.Float64("timescale", 1, "initial sim time scale (0 starts paused)")
+	schedule := flag.String("schedule", "", "timetable file (.csv or .json) to spawn flights from instead of at random")
+	snapshot := flag.String("snapshot", "", "file to save the simulator state to on SIGTERM or POST /admin/snapshot")
+	restore := flag.String("restore", "", "snapshot file to restore the simulator state from at startup")
+	record := flag.String("record", "", "append every broadcast message to this recording file")
+	replay := flag.String("replay", "", "serve this recording on /ws/flights instead of running the simulator")
+	replaySpeed := flag.Float64("replay-speed", 1, "playback speed for -replay")
+	slowClient := flag.String("slow-client", string(simulator.DefaultClientQueue.Policy), "what to do when a client's send queue is full: drop-oldest, coalesce or disconnect")
+	sendQueue := flag.Int("send-queue", simulator.DefaultClientQueue.Size, "frames buffered per WebSocket client")
+	separationNM := flag.Float64("separation-nm", simulator.DefaultConflictConfig.LateralNM, "lateral separation minimum in nm for conflict detection (0 disables it)")
+	separationFt := flag.Float64("separation-ft", simulator.DefaultConflictConfig.VerticalFt, "vertical separation minimum in feet for conflict detection")
+	lookAhead := flag.Duration("conflict-lookahead", simulator.DefaultConflictConfig.LookAhead, "how far ahead, in sim time, conflicts are predicted")
+	windSpec := flag.String("wind", "jetstream", "wind model: calm, jetstream, or a gridded wind file (.json)")
+	runways := flag.String("runways", "", "runway file in the OurAirports runways.csv format; airports without one get a made-up runway")
+	airways := flag.String("airways", "", "airway network file (.json) to route flights along instead of flying direct")
+	arrivalRate := flag.Float64("arrival-rate", simulator.DefaultCapacityConfig.Arrivals, "landings per real hour per runway in use; later arrivals hold (0 lifts the limit)")
+	departureRate := flag.Float64("departure-rate", simulator.DefaultCapacityConfig.Departures, "take-offs per real hour per runway in use; later departures wait on the runway (0 lifts the limit)")
+	weatherCells := flag.Int("weather-cells", simulator.DefaultWeatherCells, "storm cells kept alive for flights to route around (0 disables weather)")
+	flag.Parse()
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	policy, err := simulator.ParseSlowClientPolicy(*slowClient)
+	if err != nil {
+		log.Fatalf("Invalid -slow-client: %v", err)
+	}
+	queue := simulator.ClientQueue{Policy: policy, Size: *sendQueue}
+	if !(*replaySpeed > 0 && *replaySpeed <= simulator.MaxTimeScale) {
+		log.Fatalf("Invalid -replay-speed: must be above 0 and at most %g", simulator.MaxTimeScale)
+	}
+
 	shutdownTracing := telemetry.InitTracing("flight-simulator")
 	defer shutdownTracing()
 
 	shutdownLogs := telemetry.InitLogs("flight-simulator")
 	defer shutdownLogs()
 
+	if *replay != "" {
+		runReplay(*replay, *replaySpeed, queue)
+		return
+	}
+
 	airports := simulator.NewAirportStore()
 	airports.Load(data.AirportPath)
+	if *runways != "" {
+		n, err := airports.LoadRunways(*runways)
+		if err != nil {
+			log.Fatalf("Failed to load runways: %v", err)
+		}
+		log.Printf("Loaded runways for %d airports from %s", n, *runways)
+	}
+
+	clock := simulator.NewSimClock(time.Now())
+	var opts []simulator.Option
+	if set["seed"] {
+		startAt, err := time.Parse(time.RFC3339, *start)
+		if err != nil {
+			log.Fatalf("Invalid -start: %v", err)
+		}
+		clock = simulator.NewSimClock(startAt)
+		opts = append(opts, simulator.WithSeed(*seed))
+	}
+	opts = append(opts, simulator.WithClock(clock), simulator.WithClientQueue(queue), simulator.WithConflicts(simulator.ConflictConfig{
+		LateralNM: *separationNM, VerticalFt: *separationFt, LookAhead: *lookAhead,
+	}))
+	windField, err := wind.Load(*windSpec)
+	if err != nil {
+		log.Fatalf("Invalid -wind: %v", err)
+	}
+	opts = append(opts, simulator.WithWind(windField), simulator.WithWeather(*weatherCells), simulator.WithCapacity(simulator.CapacityConfig{
+		Arrivals: *arrivalRate, Departures: *departureRate,
+	}))
+	if *airways != "" {
+		network, err := nav.Load(*airways)
+		if err != nil {
+			log.Fatalf("Failed to load airways: %v", err)
+		}
+		log.Printf("Loaded %d airways over %d fixes from %s", network.Airways(), network.Fixes(), *airways)
+		opts = append(opts, simulator.WithAirways(network))
+	}
+	if *schedule != "" {
+		timetable, err := simulator.LoadTimetable(*schedule)
+		if err != nil {
+			log.Fatalf("Failed to load timetable: %v", err)
+		}
+		opts = append(opts, simulator.WithTimetable(timetable))
+	}
+	if *snapshot != "" {
+		opts = append(opts, simulator.WithSnapshotPath(*snapshot))
+	}
+	var recorder *recording.Recorder
+	if *record != "" {
+		var err error
+		if recorder, err = recording.NewRecorder(*record); err != nil {
+			log.Fatalf("Failed to open recording: %v", err)
+		}
+		opts = append(opts, simulator.WithRecorder(recorder))
+	}
 
-	sim := simulator.New(data.UpdateHz, data.GeoJSONFlightsHz, airports)
+	sim := simulator.New(data.UpdateHz, data.GeoJSONFlightsHz, airports, opts...)
+	restored := false
+	if *restore != "" {
+		snap, err := simulator.LoadSnapshot(*restore)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			log.Printf("No snapshot at %s, starting fresh", *restore)
+		case err != nil:
+			log.Fatalf("Failed to read snapshot: %v", err)
+		default:
+			if err := sim.Restore(snap); err != nil {
+				log.Fatalf("Failed to restore snapshot: %v", err)
+			}
+			restored = true
+			telemetry.LogInfo("Restored snapshot", "path", *restore)
+			log.Printf("Restored %d flights from %s", sim.FlightCount(), *restore)
+		}
+	}
+	// A restored simulation keeps its saved time scale unless one is given
+	if !restored || set["timescale"] {
+		if err := sim.SetTimeScale(*timeScale); err != nil {
+			log.Fatalf("Invalid -timescale: %v", err)
+		}
+	}
 
 	shutdownMetrics := telemetry.InitMetrics("flight-simulator", sim.FlightCount)
 	defer shutdownMetrics()
 
 	router := simulator.NewRouter(sim, airports)
+	grpcServer := simulator.NewGRPCServer(sim, airports)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go sim.Start(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		sim.Start(ctx)
+		close(stopped)
+	}()
 
 	go func() {
 		if err := simulator.StartServer(data.ServerPort, router); err != nil {
@@ -41,8 +167,16 @@ func main() {
 		}
 	}()
 
+	go func() {
+		if err := simulator.StartGRPCServer(data.GRPCPort, grpcServer); err != nil {
+			telemetry.LogError("gRPC server failed to start", err, "port", data.GRPCPort)
+			log.Fatalf("gRPC server failed to start: %v", err)
+		}
+	}()
+
 	telemetry.LogInfo("Flight Simulator started successfully",
 		"port", data.ServerPort,
+		"grpcPort", data.GRPCPort,
 		"updateHz", data.UpdateHz,
 		"geoJSONFlightsHz", data.GeoJSONFlightsHz)
 	log.Printf("Flight Simulator started successfully")
@@ -51,5 +185,54 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
+	grpcServer.Stop()
 	cancel()
+	<-stopped
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			log.Printf("Failed to close recording: %v", err)
+		}
+		if n := recorder.Dropped(); n > 0 {
+			log.Printf("Recording dropped %d frames because writing fell behind", n)
+		}
+	}
+	if *snapshot != "" {
+		if err := sim.SaveSnapshot(*snapshot); err != nil {
+			telemetry.LogError("Failed to save snapshot", err, "path", *snapshot)
+			log.Printf("Failed to save snapshot: %v", err)
+		} else {
+			log.Printf("Saved snapshot to %s", *snapshot)
+		}
+	}
+}
+
+func runReplay(path string, speed float64, queue simulator.ClientQueue) {
+	reader, err := recording.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open recording: %v", err)
+	}
+	defer reader.Close()
+
+	player := simulator.NewPlayer(reader, speed, queue)
+	router := simulator.NewReplayRouter(player)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go player.Start(ctx)
+
+	go func() {
+		if err := simulator.StartServer(data.ServerPort, router); err != nil {
+			telemetry.LogError("Server failed to start", err, "port", data.ServerPort)
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	}()
+
+	telemetry.LogInfo("Replay started", "path", path, "port", data.ServerPort)
+	log.Printf("Replaying %d frames from %s (%s to %s)", reader.Len(), path,
+		reader.Start().Format(time.RFC3339), reader.End().Format(time.RFC3339))
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
 }
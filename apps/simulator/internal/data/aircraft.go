@@ -0,0 +1,63 @@
+package data
+
+const (
+	CategoryTurboprop  = "turboprop"
+	CategoryRegional   = "regional"
+	CategoryNarrowbody = "narrowbody"
+	CategoryWidebody   = "widebody"
+
+	// The phase speeds in airline.go are tuned for an airliner cruising at
+	// this TAS (kt); each type scales them by its own cruise TAS.
+	ReferenceCruiseTAS = 450.0
+	ClimbSpeedRatio    = 0.65
+	DescentSpeedRatio  = 0.67
+)
+
+type AircraftType struct {
+	Code           string
+	Name           string
+	Category       string
+	CruiseTAS      float64 // kt
+	ClimbRate      float64 // ft/min
+	DescentRate    float64 // ft/min
+	ServiceCeiling float64 // ft
+	Range          float64 // nm
+}
+
+var AircraftTypes = []AircraftType{
+	{"AT72", "ATR 72-600", CategoryTurboprop, 275, 1400, 1500, 25000, 825},
+	{"E175", "Embraer E175", CategoryRegional, 430, 2800, 2000, 41000, 2000},
+	{"CRJ9", "Bombardier CRJ900", CategoryRegional, 447, 2500, 2000, 41000, 1550},
+	{"A320", "Airbus A320neo", CategoryNarrowbody, 447, 2500, 2000, 39000, 3300},
+	{"B738", "Boeing 737-800", CategoryNarrowbody, 453, 2500, 2000, 41000, 2935},
+	{"A321", "Airbus A321neo", CategoryNarrowbody, 447, 2300, 2000, 39000, 3500},
+	{"B789", "Boeing 787-9", CategoryWidebody, 488, 2200, 2000, 43000, 7530},
+	{"A359", "Airbus A350-900", CategoryWidebody, 488, 2300, 2000, 43100, 8100},
+	{"B77W", "Boeing 777-300ER", CategoryWidebody, 490, 2000, 2000, 43100, 7370},
+}
+
+// Share of each category in the spawned fleet.
+var CategoryWeights = map[string]float64{
+	CategoryTurboprop:  0.1,
+	CategoryRegional:   0.2,
+	CategoryNarrowbody: 0.5,
+	CategoryWidebody:   0.2,
+}
+
+var aircraftTypesByCode = func() map[string]AircraftType {
+	m := make(map[string]AircraftType, len(AircraftTypes))
+	for _, t := range AircraftTypes {
+		m[t.Code] = t
+	}
+	return m
+}()
+
+func LookupAircraftType(code string) (AircraftType, bool) {
+	t, ok := aircraftTypesByCode[code]
+	return t, ok
+}
+
+// SpeedScale converts the reference phase speeds to this type's performance.
+func (t AircraftType) SpeedScale() float64 {
+	return t.CruiseTAS / ReferenceCruiseTAS
+}
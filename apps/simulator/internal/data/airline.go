@@ -6,8 +6,23 @@ const (
 	SpeedCruise  = 30000.0
 	SpeedDescent = 21000.0
 	SpeedLanding = 15000.0
+	SpeedHolding = 15000.0
+
+	// SpeedCompression is how much faster than real aircraft the phase
+	// speeds move; real-world speeds such as wind are scaled by it.
+	SpeedCompression = SpeedCruise / ReferenceCruiseTAS
+
+	TakeoffAltitude   = 1500.0
+	ApproachAltitude  = 3000.0
+	MinCruiseAltitude = 5000.0
+
+	// Random spawn mode ramps up to TargetFlights and never exceeds MaxFlights
+	InitialFlights = 50
+	TargetFlights  = 2000
+	MaxFlights     = 2200
 
 	ServerPort       = "8080"
+	GRPCPort         = "50051"
 	UpdateHz         = 6
 	AirportPath      = "data/airports.iata.geojson"
 	GeoJSONFlightsHz = 2
@@ -16,13 +31,38 @@ const (
 type Airline struct {
 	Name     string
 	ICAOCode string
+	Country  string
+	Hubs     []string
 }
 
 var Airlines = []Airline{
-	{"United", "UAL"},
-	{"American", "AAL"},
-	{"Delta", "DL"},
-	{"Southwest", "SWA"},
-	{"JetBlue", "JBU"},
-	{"Alaska", "ASA"},
+	{"United", "UAL", "US", []string{"ORD", "DEN", "IAH", "EWR", "SFO", "IAD"}},
+	{"American", "AAL", "US", []string{"DFW", "CLT", "ORD", "PHL", "MIA", "PHX"}},
+	{"Delta", "DL", "US", []string{"ATL", "DTW", "MSP", "SLC", "JFK", "SEA"}},
+	{"Southwest", "SWA", "US", []string{"MDW", "BWI", "LAS", "DEN", "PHX"}},
+	{"JetBlue", "JBU", "US", []string{"JFK", "BOS", "FLL"}},
+	{"Alaska", "ASA", "US", []string{"SEA", "ANC", "PDX", "SFO"}},
+	{"Air Canada", "ACA", "CA", []string{"YYZ", "YVR", "YUL"}},
+	{"Aeromexico", "AMX", "MX", []string{"MEX"}},
+	{"LATAM", "LAN", "CL", []string{"SCL", "GRU", "LIM"}},
+	{"Lufthansa", "DLH", "DE", []string{"FRA", "MUC"}},
+	{"British Airways", "BAW", "GB", []string{"LHR"}},
+	{"Air France", "AFR", "FR", []string{"CDG"}},
+	{"Turkish", "THY", "TR", []string{"IST"}},
+	{"Emirates", "UAE", "AE", []string{"DXB"}},
+	{"Qatar", "QTR", "QA", []string{"DOH"}},
+	{"Ethiopian", "ETH", "ET", []string{"ADD"}},
+	{"Air India", "AIC", "IN", []string{"DEL", "BOM"}},
+	{"China Eastern", "CES", "CN", []string{"PVG"}},
+	{"Cathay Pacific", "CPA", "HK", []string{"HKG"}},
+	{"Singapore", "SIA", "SG", []string{"SIN"}},
+	{"ANA", "ANA", "JP", []string{"HND", "NRT"}},
+	{"Qantas", "QFA", "AU", []string{"SYD", "MEL"}},
+}
+
+// Relative spawn weight of each airport size class in airports.iata.geojson.
+var AirportTypeWeights = map[string]float64{
+	"large_airport":  1.0,
+	"medium_airport": 0.3,
+	"small_airport":  0.05,
 }
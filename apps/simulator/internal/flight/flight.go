@@ -9,6 +9,7 @@ const (
 	Descent Phase = "descent"
 	Landing Phase = "landing"
 	Landed  Phase = "landed"
+	Holding Phase = "holding"
 )
 
 type Position struct {
@@ -23,23 +24,58 @@ type Velocity struct {
 	Z float64 `json:"z"`
 }
 
+// Waypoint is a fix on a flight's route. Airway is the airway flown to
+// reach it, empty for a direct leg.
+type Waypoint struct {
+	Name      string  `json:"name"`
+	Airway    string  `json:"airway,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (w Waypoint) Position() Position {
+	return Position{Latitude: w.Latitude, Longitude: w.Longitude}
+}
+
+// State is one flight. Speeds are knots, time-compressed for the globe.
+// Bearing is the track over the ground and Speed the true airspeed; Track
+// and TrueAirspeed repeat them next to Heading and GroundSpeed, which the
+// wind sets apart. Waypoints run from the departure airport to the arrival
+// airport, and NextWaypoint indexes the fix being flown to.
 type State struct {
-	ID                 string   `json:"id"`
-	CallSign           string   `json:"callSign"`
-	Airline            string   `json:"airline"`
-	DepartureAirport   string   `json:"departureAirport"`
-	ArrivalAirport     string   `json:"arrivalAirport"`
-	Phase              Phase    `json:"phase"`
-	Position           Position `json:"position"`
-	Velocity           Velocity `json:"velocity"`
-	Bearing            float64  `json:"bearing"`
-	Speed              float64  `json:"speed"`
-	Altitude           float64  `json:"altitude"`
-	Progress           float64  `json:"progress"`
-	DistanceRemaining  float64  `json:"distanceRemaining"`
-	ScheduledDeparture string   `json:"scheduledDeparture"`
-	ScheduledArrival   string   `json:"scheduledArrival"`
-	EstimatedArrival   string   `json:"estimatedArrival"`
-	LastComputedAt     string   `json:"lastComputedAt"`
-	TraceID            string   `json:"traceID"`
+	ID                 string     `json:"id"`
+	CallSign           string     `json:"callSign"`
+	Airline            string     `json:"airline"`
+	AircraftType       string     `json:"aircraftType"`
+	DepartureAirport   string     `json:"departureAirport"`
+	ArrivalAirport     string     `json:"arrivalAirport"`
+	DepartureRunway    string     `json:"departureRunway"`
+	ArrivalRunway      string     `json:"arrivalRunway"`
+	Phase              Phase      `json:"phase"`
+	Position           Position   `json:"position"`
+	Velocity           Velocity   `json:"velocity"`
+	Bearing            float64    `json:"bearing"`
+	Speed              float64    `json:"speed"`
+	Track              float64    `json:"track"`
+	Heading            float64    `json:"heading"`
+	TrueAirspeed       float64    `json:"trueAirspeed"`
+	GroundSpeed        float64    `json:"groundSpeed"`
+	Altitude           float64    `json:"altitude"`
+	VerticalSpeed      float64    `json:"verticalSpeed"`
+	CruiseAltitude     float64    `json:"cruiseAltitude"`
+	TopOfClimb         Position   `json:"topOfClimb"`
+	TopOfDescent       Position   `json:"topOfDescent"`
+	Progress           float64    `json:"progress"`
+	DistanceRemaining  float64    `json:"distanceRemaining"`
+	Route              string     `json:"route"`
+	Waypoints          []Waypoint `json:"waypoints"`
+	NextWaypoint       int        `json:"nextWaypoint"`
+	NextFix            string     `json:"nextFix"`
+	FixBearing         float64    `json:"fixBearing"`
+	FixDistance        float64    `json:"fixDistance"`
+	ScheduledDeparture string     `json:"scheduledDeparture"`
+	ScheduledArrival   string     `json:"scheduledArrival"`
+	EstimatedArrival   string     `json:"estimatedArrival"`
+	LastComputedAt     string     `json:"lastComputedAt"`
+	TraceID            string     `json:"traceID"`
 }
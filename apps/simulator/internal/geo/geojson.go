@@ -35,3 +35,11 @@ func NewLineStringFeature(coords [][]float64, props map[string]interface{}) Feat
 		Properties: props,
 	}
 }
+
+func NewPolygonFeature(rings [][][]float64, props map[string]interface{}) Feature {
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Polygon", Coordinates: rings},
+		Properties: props,
+	}
+}
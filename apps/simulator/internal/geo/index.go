@@ -0,0 +1,166 @@
+package geo
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/paulmach/orb"
+)
+
+// earthRadiusNM matches the sphere CalculateDistance uses.
+const earthRadiusNM = orb.EarthRadius / 1852.0
+
+// Index is a static k-d tree over points on the sphere. Points are stored as
+// unit vectors, so queries work across the antimeridian and near the poles.
+// It refers to points by their position in the slice it was built from.
+type Index struct {
+	nodes []indexNode
+}
+
+type indexNode struct {
+	item int
+	v    [3]float64
+}
+
+// Neighbor is a point found by a query and its great-circle distance in
+// nautical miles.
+type Neighbor struct {
+	Item     int
+	Distance float64
+}
+
+// NewIndex builds an index over n points, where pos returns the longitude
+// and latitude of point i.
+func NewIndex(n int, pos func(i int) (lon, lat float64)) *Index {
+	nodes := make([]indexNode, n)
+	for i := range nodes {
+		lon, lat := pos(i)
+		nodes[i] = indexNode{item: i, v: unitVector(lon, lat)}
+	}
+	build(nodes, 0)
+	return &Index{nodes: nodes}
+}
+
+// Len returns the number of indexed points.
+func (x *Index) Len() int {
+	return len(x.nodes)
+}
+
+// build lays the tree out in place: the median of each range is its root,
+// split on axis depth%3, with the lower half before it and the upper after.
+func build(nodes []indexNode, depth int) {
+	if len(nodes) <= 1 {
+		return
+	}
+	axis := depth % 3
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].v[axis] < nodes[j].v[axis] })
+	mid := len(nodes) / 2
+	build(nodes[:mid], depth+1)
+	build(nodes[mid+1:], depth+1)
+}
+
+// Nearest returns up to k points closest to lon, lat, nearest first.
+func (x *Index) Nearest(lon, lat float64, k int) []Neighbor {
+	if k <= 0 {
+		return nil
+	}
+	q := unitVector(lon, lat)
+	h := &neighborHeap{}
+	var search func(nodes []indexNode, depth int)
+	search = func(nodes []indexNode, depth int) {
+		if len(nodes) == 0 {
+			return
+		}
+		mid, axis := len(nodes)/2, depth%3
+		n := nodes[mid]
+		if d := chord2(q, n.v); h.Len() < k {
+			heap.Push(h, Neighbor{Item: n.item, Distance: d})
+		} else if d < (*h)[0].Distance {
+			(*h)[0] = Neighbor{Item: n.item, Distance: d}
+			heap.Fix(h, 0)
+		}
+		near, far := nodes[:mid], nodes[mid+1:]
+		diff := q[axis] - n.v[axis]
+		if diff > 0 {
+			near, far = far, near
+		}
+		search(near, depth+1)
+		if h.Len() < k || diff*diff < (*h)[0].Distance {
+			search(far, depth+1)
+		}
+	}
+	search(x.nodes, 0)
+
+	result := make([]Neighbor, h.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		nb := heap.Pop(h).(Neighbor)
+		nb.Distance = chordToNM(nb.Distance)
+		result[i] = nb
+	}
+	return result
+}
+
+// Within returns the points no more than radius nautical miles from lon,
+// lat, in no particular order.
+func (x *Index) Within(lon, lat, radius float64) []Neighbor {
+	q := unitVector(lon, lat)
+	limit := nmToChord2(radius)
+	var result []Neighbor
+	var search func(nodes []indexNode, depth int)
+	search = func(nodes []indexNode, depth int) {
+		if len(nodes) == 0 {
+			return
+		}
+		mid, axis := len(nodes)/2, depth%3
+		n := nodes[mid]
+		if d := chord2(q, n.v); d <= limit {
+			result = append(result, Neighbor{Item: n.item, Distance: chordToNM(d)})
+		}
+		diff := q[axis] - n.v[axis]
+		if diff <= 0 || diff*diff <= limit {
+			search(nodes[:mid], depth+1)
+		}
+		if diff >= 0 || diff*diff <= limit {
+			search(nodes[mid+1:], depth+1)
+		}
+	}
+	search(x.nodes, 0)
+	return result
+}
+
+func unitVector(lon, lat float64) [3]float64 {
+	lonR, latR := lon*math.Pi/180, lat*math.Pi/180
+	return [3]float64{math.Cos(latR) * math.Cos(lonR), math.Cos(latR) * math.Sin(lonR), math.Sin(latR)}
+}
+
+// chord2 is the squared straight-line distance between two unit vectors,
+// which orders points the same way as great-circle distance.
+func chord2(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+func chordToNM(c2 float64) float64 {
+	return 2 * math.Asin(math.Min(1, math.Sqrt(c2)/2)) * earthRadiusNM
+}
+
+func nmToChord2(nm float64) float64 {
+	angle := math.Min(math.Pi, nm/earthRadiusNM)
+	c := 2 * math.Sin(angle/2)
+	return c * c
+}
+
+// neighborHeap is a max-heap on Distance, holding the k best so far.
+type neighborHeap []Neighbor
+
+func (h neighborHeap) Len() int            { return len(h) }
+func (h neighborHeap) Less(i, j int) bool  { return h[i].Distance > h[j].Distance }
+func (h neighborHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap) Push(x interface{}) { *h = append(*h, x.(Neighbor)) }
+func (h *neighborHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
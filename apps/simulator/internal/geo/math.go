@@ -14,10 +14,11 @@ func CalculateBearing(from, to flight.Position) float64 {
 	return geo.Bearing(p1, p2)
 }
 
+// CalculateDistance is the great-circle distance in nautical miles.
 func CalculateDistance(from, to flight.Position) float64 {
 	p1 := orb.Point{from.Longitude, from.Latitude}
 	p2 := orb.Point{to.Longitude, to.Latitude}
-	return geo.Distance(p1, p2) / 1852.0
+	return geo.DistanceHaversine(p1, p2) / 1852.0
 }
 
 func InterpolatePosition(from, to flight.Position, progress float64) flight.Position {
@@ -25,30 +26,57 @@ func InterpolatePosition(from, to flight.Position, progress float64) flight.Posi
 	p2 := orb.Point{to.Longitude, to.Latitude}
 
 	bearing := geo.Bearing(p1, p2)
-	totalDist := geo.Distance(p1, p2)
+	totalDist := geo.DistanceHaversine(p1, p2)
 	result := geo.PointAtBearingAndDistance(p1, bearing, totalDist*progress)
 
 	return flight.Position{
 		Latitude:  result.Lat(),
 		Longitude: result.Lon(),
-		Altitude:  from.Altitude,
+		Altitude:  from.Altitude + (to.Altitude-from.Altitude)*progress,
 	}
 }
 
+// Destination is the point distance nautical miles from from along bearing.
+func Destination(from flight.Position, bearing, distance float64) flight.Position {
+	p := geo.PointAtBearingAndDistance(orb.Point{from.Longitude, from.Latitude}, bearing, distance*1852.0)
+	return flight.Position{Longitude: p.Lon(), Latitude: p.Lat(), Altitude: from.Altitude}
+}
+
+// CrossTrack returns how far p lies right (positive) or left of the great
+// circle from from to to, and how far along it p's foot is, in nautical
+// miles. along is negative when p is behind from.
+func CrossTrack(from, to, p flight.Position) (cross, along float64) {
+	radius := orb.EarthRadius / 1852.0
+	d := CalculateDistance(from, p) / radius
+	theta := (CalculateBearing(from, p) - CalculateBearing(from, to)) * math.Pi / 180
+	xt := math.Asin(math.Sin(d) * math.Sin(theta))
+	at := math.Acos(math.Max(-1, math.Min(1, math.Cos(d)/math.Cos(xt))))
+	if math.Cos(theta) < 0 {
+		at = -at
+	}
+	return xt * radius, at * radius
+}
+
 func SpeedToVelocity(speed, bearing float64) flight.Velocity {
 	rad := bearing * math.Pi / 180
 	return flight.Velocity{X: speed * math.Sin(rad), Y: speed * math.Cos(rad), Z: 0}
 }
 
+// EffectiveSpeed is how fast, in knots, GreatCircleStep moves a flight
+// flying at speed; the fast phases move twice as fast.
+func EffectiveSpeed(speed float64) float64 {
+	if speed > 10000 {
+		return speed * 2
+	}
+	return speed
+}
+
 func GreatCircleStep(current, dest flight.Position, speed, dt float64) flight.Position {
 	dist := CalculateDistance(current, dest)
 	if dist < 0.1 {
 		return dest
 	}
-	step := speed * dt / 3600.0
-	if speed > 10000 {
-		step *= 2.0
-	}
+	step := EffectiveSpeed(speed) * dt / 3600.0
 	if step >= dist {
 		return dest
 	}
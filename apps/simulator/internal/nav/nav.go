@@ -0,0 +1,189 @@
+package nav
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+// Fix is a named point airways run between.
+type Fix struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Airway is a sequence of fixes flown one after another. OneWay airways,
+// such as the day's oceanic tracks, are only flown in the order listed.
+type Airway struct {
+	Name   string   `json:"name"`
+	Fixes  []string `json:"fixes"`
+	OneWay bool     `json:"oneWay,omitempty"`
+}
+
+// Network is the airway graph routes are planned over.
+type Network struct {
+	fixes   []Fix
+	byName  map[string]int
+	edges   [][]edge
+	airways int
+	// joinable lists the fixes on at least one airway, and index covers them
+	joinable []int
+	index    *geo.Index
+}
+
+type edge struct {
+	to       int
+	airway   string
+	distance float64
+}
+
+type networkFile struct {
+	Fixes   []Fix    `json:"fixes"`
+	Airways []Airway `json:"airways"`
+}
+
+// Load reads a network from a JSON file:
+//
+//	{"fixes": [{"name": "MERIT", "latitude": 41.38, "longitude": -73.14}, ...],
+//	 "airways": [{"name": "NATA", "fixes": ["MERIT", "5750N", ...], "oneWay": true}, ...]}
+//
+// Airways may name latitude/longitude fixes in the ARINC 424 short form,
+// e.g. 5750N for 57°N 50°W or 40E60 for 40°N 160°E, without listing them.
+func Load(path string) (*Network, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file networkFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse airways %s: %w", path, err)
+	}
+	n, err := New(file.Fixes, file.Airways)
+	if err != nil {
+		return nil, fmt.Errorf("airways %s: %w", path, err)
+	}
+	return n, nil
+}
+
+// New builds a network from fixes and the airways joining them.
+func New(fixes []Fix, airways []Airway) (*Network, error) {
+	n := &Network{byName: make(map[string]int, len(fixes)), airways: len(airways)}
+	for _, f := range fixes {
+		if _, ok := n.byName[f.Name]; ok {
+			return nil, fmt.Errorf("duplicate fix %q", f.Name)
+		}
+		n.add(f)
+	}
+	for _, a := range airways {
+		if len(a.Fixes) < 2 {
+			return nil, fmt.Errorf("airway %q needs at least two fixes", a.Name)
+		}
+		path := make([]int, len(a.Fixes))
+		for i, name := range a.Fixes {
+			id, ok := n.byName[name]
+			if !ok {
+				lat, lon, ok := parseLatLon(name)
+				if !ok {
+					return nil, fmt.Errorf("airway %q: unknown fix %q", a.Name, name)
+				}
+				id = n.add(Fix{Name: name, Latitude: lat, Longitude: lon})
+			}
+			path[i] = id
+		}
+		for i := 1; i < len(path); i++ {
+			from, to := path[i-1], path[i]
+			d := geo.CalculateDistance(n.position(from), n.position(to))
+			n.edges[from] = append(n.edges[from], edge{to: to, airway: a.Name, distance: d})
+			if !a.OneWay {
+				n.edges[to] = append(n.edges[to], edge{to: from, airway: a.Name, distance: d})
+			}
+		}
+	}
+	// The last fix of a one-way airway has no way on but can still be flown to
+	onAirway := make([]bool, len(n.fixes))
+	for id, edges := range n.edges {
+		for _, e := range edges {
+			onAirway[id], onAirway[e.to] = true, true
+		}
+	}
+	for id, ok := range onAirway {
+		if ok {
+			n.joinable = append(n.joinable, id)
+		}
+	}
+	n.index = geo.NewIndex(len(n.joinable), func(i int) (float64, float64) {
+		f := n.fixes[n.joinable[i]]
+		return f.Longitude, f.Latitude
+	})
+	return n, nil
+}
+
+// Fixes returns how many fixes the network has, named or latitude/longitude.
+func (n *Network) Fixes() int {
+	return len(n.fixes)
+}
+
+// Airways returns how many airways the network was built from.
+func (n *Network) Airways() int {
+	return n.airways
+}
+
+func (n *Network) add(f Fix) int {
+	n.byName[f.Name] = len(n.fixes)
+	n.fixes = append(n.fixes, f)
+	n.edges = append(n.edges, nil)
+	return len(n.fixes) - 1
+}
+
+func (n *Network) position(id int) flight.Position {
+	return flight.Position{Latitude: n.fixes[id].Latitude, Longitude: n.fixes[id].Longitude}
+}
+
+// parseLatLon reads the ARINC 424 five-character latitude/longitude fix
+// names. The letter gives the hemispheres (N: north/west, E: north/east,
+// S: south/east, W: south/west); it comes last for longitudes under 100°
+// and third, standing in for the hundreds digit, otherwise.
+func parseLatLon(name string) (float64, float64, bool) {
+	if len(name) != 5 {
+		return 0, 0, false
+	}
+	var digits string
+	var letter byte
+	var hundred float64
+	switch {
+	case isHemisphere(name[4]):
+		digits, letter = name[:4], name[4]
+	case isHemisphere(name[2]):
+		digits, letter, hundred = name[:2]+name[3:], name[2], 100
+	default:
+		return 0, 0, false
+	}
+	for i := range digits {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, 0, false
+		}
+	}
+	v, _ := strconv.Atoi(digits)
+	lat, lon := float64(v/100), float64(v%100)+hundred
+	if lat > 90 || lon > 180 {
+		return 0, 0, false
+	}
+	switch letter {
+	case 'N':
+		lon = -lon
+	case 'S':
+		lat = -lat
+	case 'W':
+		lat, lon = -lat, -lon
+	}
+	return lat, lon, true
+}
+
+func isHemisphere(c byte) bool {
+	return c == 'N' || c == 'E' || c == 'S' || c == 'W'
+}
@@ -0,0 +1,165 @@
+package nav
+
+import (
+	"container/heap"
+	"strings"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+const (
+	// MaxDirect is the furthest, in nm, a route flies direct to join the
+	// network after departure or after leaving it for the arrival.
+	MaxDirect = 1200.0
+	// MaxStretch is how much longer than the great circle an airway route
+	// may be before flying direct is preferred.
+	MaxStretch = 1.1
+	// directCost weighs direct legs against airway ones when choosing a
+	// route, so routes keep to airways rather than leave them early.
+	directCost = 1.25
+)
+
+// Route plans the shortest way from one airport to another along the
+// network: direct to an airway, along one or more airways, and direct to
+// the destination. The waypoints start at from and end at to. It reports
+// false if no such route is within MaxStretch of the great circle.
+func (n *Network) Route(from, to flight.Waypoint) ([]flight.Waypoint, bool) {
+	fromPos, toPos := from.Position(), to.Position()
+	limit := geo.CalculateDistance(fromPos, toPos) * MaxStretch
+	if len(n.joinable) == 0 {
+		return nil, false
+	}
+
+	exits := make(map[int]float64)
+	for _, nb := range n.index.Within(toPos.Longitude, toPos.Latitude, MaxDirect) {
+		exits[n.joinable[nb.Item]] = nb.Distance
+	}
+	if len(exits) == 0 {
+		return nil, false
+	}
+
+	// A search state is a fix and whether it was reached along an airway;
+	// only those may leave for the destination, so every route uses at
+	// least one airway.
+	s := &search{
+		network: n, to: toPos, limit: limit,
+		best: make(map[int]searchNode), prev: make(map[int]int), via: make(map[int]string),
+	}
+	for _, nb := range n.index.Within(fromPos.Longitude, fromPos.Latitude, MaxDirect) {
+		s.relax(joinState(n.joinable[nb.Item]), searchNode{}, nb.Distance, directCost, originState, "")
+	}
+	for s.open.Len() > 0 {
+		cur := heap.Pop(&s.open).(searchNode)
+		if cur.cost > s.best[cur.state].cost {
+			continue // superseded
+		}
+		if cur.state == goalState {
+			return s.path(from, to), true
+		}
+		id, alongAirway := cur.state/2, cur.state%2 == 1
+		if alongAirway {
+			if d, ok := exits[id]; ok {
+				s.relax(goalState, cur, d, directCost, cur.state, "")
+			}
+		}
+		for _, e := range n.edges[id] {
+			s.relax(airwayState(e.to), cur, e.distance, 1, cur.state, e.airway)
+		}
+	}
+	return nil, false
+}
+
+const (
+	originState = -1
+	goalState   = -2
+)
+
+func joinState(id int) int   { return id * 2 }
+func airwayState(id int) int { return id*2 + 1 }
+
+type search struct {
+	network *Network
+	to      flight.Position
+	limit   float64
+	open    searchHeap
+	best    map[int]searchNode
+	prev    map[int]int
+	via     map[int]string
+}
+
+// relax records a cheaper way to state by a leg of distance nm from cur,
+// unless even flying straight on from it to the destination would break
+// the stretch limit.
+func (s *search) relax(state int, cur searchNode, distance, weight float64, from int, airway string) {
+	next := searchNode{state: state, cost: cur.cost + distance*weight, length: cur.length + distance}
+	if old, ok := s.best[state]; ok && old.cost <= next.cost {
+		return
+	}
+	rest := 0.0
+	if state != goalState {
+		rest = geo.CalculateDistance(s.network.position(state/2), s.to)
+	}
+	if next.length+rest > s.limit {
+		return
+	}
+	next.estimate = next.cost + rest
+	s.best[state], s.prev[state], s.via[state] = next, from, airway
+	heap.Push(&s.open, next)
+}
+
+func (s *search) path(from, to flight.Waypoint) []flight.Waypoint {
+	route := []flight.Waypoint{{Name: to.Name, Latitude: to.Latitude, Longitude: to.Longitude}}
+	for state := s.prev[goalState]; state != originState; state = s.prev[state] {
+		f := s.network.fixes[state/2]
+		route = append(route, flight.Waypoint{Name: f.Name, Airway: s.via[state], Latitude: f.Latitude, Longitude: f.Longitude})
+	}
+	route = append(route, flight.Waypoint{Name: from.Name, Latitude: from.Latitude, Longitude: from.Longitude})
+	for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+		route[i], route[j] = route[j], route[i]
+	}
+	return route
+}
+
+type searchNode struct {
+	state int
+	// cost weighs direct legs by directCost; length is the plain distance
+	cost     float64
+	length   float64
+	estimate float64
+}
+
+type searchHeap []searchNode
+
+func (h searchHeap) Len() int            { return len(h) }
+func (h searchHeap) Less(i, j int) bool  { return h[i].estimate < h[j].estimate }
+func (h searchHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *searchHeap) Push(x interface{}) { *h = append(*h, x.(searchNode)) }
+func (h *searchHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// RouteString writes waypoints as an ICAO flight plan route, naming each
+// airway once between the fixes where it is joined and left:
+// "JFK DCT 5250N NATX 5220N DCT LHR".
+func RouteString(waypoints []flight.Waypoint) string {
+	if len(waypoints) == 0 {
+		return ""
+	}
+	parts := []string{waypoints[0].Name}
+	for i := 1; i < len(waypoints); i++ {
+		w := waypoints[i]
+		if w.Airway != "" && i+1 < len(waypoints) && waypoints[i+1].Airway == w.Airway {
+			continue
+		}
+		via := w.Airway
+		if via == "" {
+			via = "DCT"
+		}
+		parts = append(parts, via, w.Name)
+	}
+	return strings.Join(parts, " ")
+}
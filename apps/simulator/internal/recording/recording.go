@@ -0,0 +1,267 @@
+package recording
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A recording is an 8-byte magic followed by frames. Each frame is a fixed
+// header (recorded-at and sim time in unix ms, payload length) and a
+// flate-compressed payload, so the index is rebuilt by reading headers only.
+const (
+	magic      = "VOYREC01"
+	headerSize = 8 + 8 + 4
+	queueSize  = 64
+)
+
+var ErrBadMagic = errors.New("recording: not a voyager recording")
+
+type Frame struct {
+	RecordedAt time.Time
+	SimTime    time.Time
+	Payload    []byte
+}
+
+type Recorder struct {
+	file    *os.File
+	w       *bufio.Writer
+	frames  chan Frame
+	done    chan struct{}
+	closeMu sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+	mu      sync.Mutex
+	err     error
+}
+
+// NewRecorder appends to path, creating it if needed. A torn frame left by a
+// crash is cut off before new frames are written.
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		if _, err := file.WriteString(magic); err != nil {
+			file.Close()
+			return nil, err
+		}
+	} else {
+		_, end, err := scan(file)
+		if err == nil {
+			err = file.Truncate(end)
+		}
+		if err == nil {
+			_, err = file.Seek(end, io.SeekStart)
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	r := &Recorder{
+		file:   file,
+		w:      bufio.NewWriter(file),
+		frames: make(chan Frame, queueSize),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// Record queues a frame; compression and disk I/O happen off the caller's
+// goroutine, which is never blocked. It reports false if the frame was
+// dropped because the queue is full or the recorder is closed.
+func (r *Recorder) Record(recordedAt, simTime time.Time, payload []byte) bool {
+	r.closeMu.RLock()
+	defer r.closeMu.RUnlock()
+	if r.closed {
+		return false
+	}
+	select {
+	case r.frames <- Frame{RecordedAt: recordedAt, SimTime: simTime, Payload: payload}:
+		return true
+	default:
+		r.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped is how many frames were dropped because the disk fell behind.
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+func (r *Recorder) Close() error {
+	r.closeMu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.frames)
+	}
+	r.closeMu.Unlock()
+	<-r.done
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	var buf bytes.Buffer
+	zw, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	for f := range r.frames {
+		buf.Reset()
+		zw.Reset(&buf)
+		zw.Write(f.Payload)
+		zw.Close()
+
+		var header [headerSize]byte
+		binary.BigEndian.PutUint64(header[0:8], uint64(f.RecordedAt.UnixMilli()))
+		binary.BigEndian.PutUint64(header[8:16], uint64(f.SimTime.UnixMilli()))
+		binary.BigEndian.PutUint32(header[16:20], uint32(buf.Len()))
+		r.w.Write(header[:])
+		r.w.Write(buf.Bytes())
+		// Flush per frame so a reader or a crash sees whole frames only
+		if err := r.w.Flush(); err != nil {
+			r.mu.Lock()
+			if r.err == nil {
+				r.err = err
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+type entry struct {
+	recordedAt int64
+	simTime    int64
+	offset     int64
+	length     uint32
+}
+
+type Reader struct {
+	file  *os.File
+	index []entry
+}
+
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	index, _, err := scan(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Reader{file: file, index: index}, nil
+}
+
+// scan reads frame headers from the start of file and returns the index and
+// the offset just past the last complete frame.
+func scan(file *os.File) ([]entry, int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := info.Size()
+	head := make([]byte, len(magic))
+	if _, err := file.ReadAt(head, 0); err != nil || string(head) != magic {
+		return nil, 0, ErrBadMagic
+	}
+	var index []entry
+	offset := int64(len(magic))
+	var header [headerSize]byte
+	for offset+headerSize <= size {
+		if _, err := file.ReadAt(header[:], offset); err != nil {
+			return nil, 0, err
+		}
+		e := entry{
+			recordedAt: int64(binary.BigEndian.Uint64(header[0:8])),
+			simTime:    int64(binary.BigEndian.Uint64(header[8:16])),
+			offset:     offset + headerSize,
+			length:     binary.BigEndian.Uint32(header[16:20]),
+		}
+		if e.offset+int64(e.length) > size {
+			break
+		}
+		index = append(index, e)
+		offset = e.offset + int64(e.length)
+	}
+	return index, offset, nil
+}
+
+func (r *Reader) Len() int {
+	return len(r.index)
+}
+
+func (r *Reader) Start() time.Time {
+	if len(r.index) == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(r.index[0].recordedAt).UTC()
+}
+
+func (r *Reader) End() time.Time {
+	if len(r.index) == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(r.index[len(r.index)-1].recordedAt).UTC()
+}
+
+func (r *Reader) RecordedAt(i int) time.Time {
+	return time.UnixMilli(r.index[i].recordedAt).UTC()
+}
+
+// Search returns the index of the last frame recorded at or before t, or 0
+// when t precedes the recording.
+func (r *Reader) Search(t time.Time) int {
+	ms := t.UnixMilli()
+	i := sort.Search(len(r.index), func(i int) bool { return r.index[i].recordedAt > ms })
+	if i == 0 {
+		return 0
+	}
+	return i - 1
+}
+
+func (r *Reader) Frame(i int) (Frame, error) {
+	if i < 0 || i >= len(r.index) {
+		return Frame{}, fmt.Errorf("recording: frame %d out of range", i)
+	}
+	e := r.index[i]
+	compressed := make([]byte, e.length)
+	if _, err := r.file.ReadAt(compressed, e.offset); err != nil {
+		return Frame{}, err
+	}
+	payload, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		return Frame{}, err
+	}
+	return Frame{
+		RecordedAt: time.UnixMilli(e.recordedAt).UTC(),
+		SimTime:    time.UnixMilli(e.simTime).UTC(),
+		Payload:    payload,
+	}, nil
+}
+
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
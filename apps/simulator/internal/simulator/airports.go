@@ -0,0 +1,173 @@
+package simulator
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchResults = 10
+	maxSearchResults     = 100
+	defaultNearest       = 5
+	maxNearest           = 50
+)
+
+type airportInfo struct {
+	IATA      string  `json:"iata"`
+	ICAO      string  `json:"icao"`
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Country   string  `json:"country"`
+	Type      string  `json:"type"`
+	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+}
+
+func newAirportInfo(a Airport) airportInfo {
+	return airportInfo{
+		IATA: a.IATA, ICAO: a.ICAO, Name: a.Name, City: a.City, Country: a.Country, Type: a.Type,
+		Longitude: a.Position.Longitude, Latitude: a.Position.Latitude,
+	}
+}
+
+type nearbyAirport struct {
+	airportInfo
+	// Nautical miles.
+	Distance float64 `json:"distanceNm"`
+}
+
+// nearest returns up to k airports closest to lon, lat, nearest first.
+func (s *AirportStore) nearest(lon, lat float64, k int) []nearbyAirport {
+	if s.index == nil {
+		return nil
+	}
+	found := s.index.Nearest(lon, lat, k)
+	result := make([]nearbyAirport, len(found))
+	for i, n := range found {
+		result[i] = nearbyAirport{airportInfo: newAirportInfo(s.Airports[s.Codes[n.Item]]), Distance: n.Distance}
+	}
+	return result
+}
+
+// search matches q case-insensitively against codes, names and cities.
+// Exact IATA or ICAO codes rank first, then names or cities starting with q,
+// then any word starting with q, then anything containing it.
+func (s *AirportStore) search(q string, limit int) []Airport {
+	q = strings.ToLower(strings.TrimSpace(q))
+	if q == "" {
+		return nil
+	}
+	type match struct {
+		airport Airport
+		rank    int
+	}
+	var matches []match
+	for _, code := range s.Codes {
+		a := s.Airports[code]
+		if rank, ok := searchRank(a, q); ok {
+			matches = append(matches, match{a, rank})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return matches[i].airport.IATA < matches[j].airport.IATA
+	})
+	result := make([]Airport, 0, min(limit, len(matches)))
+	for _, m := range matches[:min(limit, len(matches))] {
+		result = append(result, m.airport)
+	}
+	return result
+}
+
+func searchRank(a Airport, q string) (int, bool) {
+	if strings.ToLower(a.IATA) == q || strings.ToLower(a.ICAO) == q {
+		return 0, true
+	}
+	best, ok := 0, false
+	for _, field := range []string{strings.ToLower(a.Name), strings.ToLower(a.City)} {
+		var rank int
+		switch {
+		case strings.HasPrefix(field, q):
+			rank = 1
+		case strings.Contains(" "+field, " "+q):
+			rank = 2
+		case strings.Contains(field, q):
+			rank = 3
+		default:
+			continue
+		}
+		if !ok || rank < best {
+			best, ok = rank, true
+		}
+	}
+	return best, ok
+}
+
+func airportSearchHandler(airports *AirportStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q := r.URL.Query().Get("q")
+		if strings.TrimSpace(q) == "" {
+			http.Error(w, "Missing required parameter: q", http.StatusBadRequest)
+			return
+		}
+		limit, err := countParam(r, "limit", defaultSearchResults, maxSearchResults)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		found := airports.search(q, limit)
+		result := make([]airportInfo, len(found))
+		for i, a := range found {
+			result[i] = newAirportInfo(a)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"airports": result})
+	}
+}
+
+func airportNearestHandler(airports *AirportStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q := r.URL.Query()
+		lat, err1 := strconv.ParseFloat(q.Get("lat"), 64)
+		lon, err2 := strconv.ParseFloat(q.Get("lon"), 64)
+		if err1 != nil || err2 != nil || !(lat >= -90 && lat <= 90) || math.IsNaN(lon) || math.IsInf(lon, 0) {
+			http.Error(w, "lat and lon must be numbers, with -90 <= lat <= 90", http.StatusBadRequest)
+			return
+		}
+		k, err := countParam(r, "k", defaultNearest, maxNearest)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"airports": airports.nearest(lon, lat, k)})
+	}
+}
+
+// countParam reads a positive integer query parameter, capped at limit.
+func countParam(r *http.Request, name string, def, limit int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, errors.New(name + " must be a positive integer")
+	}
+	return min(n, limit), nil
+}
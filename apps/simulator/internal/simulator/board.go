@@ -0,0 +1,254 @@
+package simulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/data"
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+const (
+	// boardHorizon is how far ahead timetable departures are listed.
+	boardHorizon = 2 * time.Hour
+	// boardingWindow is how long before departure a flight shows as boarding.
+	boardingWindow = 30 * time.Minute
+	// delayThreshold is how late, in real time, the estimated arrival may
+	// run before a flight shows as delayed. Arrival times are on the
+	// compressed time base of blockTime, so it is scaled down to match.
+	delayThreshold = 15 * time.Minute
+	// landedWindow is how long, in real time and scaled down alike, a
+	// flight stays on the arrivals board after it lands.
+	landedWindow = 30 * time.Minute
+	// recentArrivals is how many landed flights the arrivals boards keep.
+	recentArrivals = 256
+)
+
+type flightStatus string
+
+const (
+	statusScheduled flightStatus = "scheduled"
+	statusBoarding  flightStatus = "boarding"
+	statusDeparted  flightStatus = "departed"
+	statusEnRoute   flightStatus = "en route"
+	statusHolding   flightStatus = "holding"
+	statusLanded    flightStatus = "landed"
+	statusDelayed   flightStatus = "delayed"
+)
+
+// boardEntry is one row of a departures or arrivals board. FlightID is empty
+// for timetable flights that have not departed yet.
+type boardEntry struct {
+	FlightID           string       `json:"flightId,omitempty"`
+	CallSign           string       `json:"callSign"`
+	Airline            string       `json:"airline"`
+	AircraftType       string       `json:"aircraftType"`
+	Origin             string       `json:"origin"`
+	Destination        string       `json:"destination"`
+	ScheduledDeparture string       `json:"scheduledDeparture"`
+	ScheduledArrival   string       `json:"scheduledArrival,omitempty"`
+	EstimatedArrival   string       `json:"estimatedArrival,omitempty"`
+	Status             flightStatus `json:"status"`
+}
+
+type airportBoard struct {
+	Airport string       `json:"airport"`
+	SimTime string       `json:"simTime"`
+	Flights []boardEntry `json:"flights"`
+}
+
+type airportDetail struct {
+	airportInfo
+	ActiveDepartures int      `json:"activeDepartures"`
+	ActiveArrivals   int      `json:"activeArrivals"`
+	Runways          []Runway `json:"runways"`
+	// Runway ends in use for the current surface wind.
+	ActiveRunways []string `json:"activeRunways"`
+	// Arrivals and departures handled per real hour, 0 without a limit.
+	ArrivalRate   float64 `json:"arrivalRate"`
+	DepartureRate float64 `json:"departureRate"`
+	// Arrivals holding for a landing slot.
+	Holding int `json:"holding"`
+}
+
+func airportHandler(s *Simulator, airports *AirportStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		a, ok := airports.Airports[strings.ToUpper(r.PathValue("iata"))]
+		if !ok {
+			http.Error(w, "Airport not found", http.StatusNotFound)
+			return
+		}
+		detail := airportDetail{airportInfo: newAirportInfo(a), Runways: a.Runways}
+		for _, end := range activeRunways(a, s.flights.wind) {
+			detail.ActiveRunways = append(detail.ActiveRunways, end.Ident)
+		}
+		capacity := s.flights.capacity
+		detail.ArrivalRate = capacity.Arrivals * float64(len(detail.ActiveRunways))
+		detail.DepartureRate = capacity.Departures * float64(len(detail.ActiveRunways))
+		for _, f := range s.flights.snapshot() {
+			if f.DepartureAirport == a.IATA {
+				detail.ActiveDepartures++
+			}
+			if f.ArrivalAirport == a.IATA {
+				detail.ActiveArrivals++
+				if f.Phase == flight.Holding {
+					detail.Holding++
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(detail)
+	}
+}
+
+// boardHandler serves the departures board when departures is set and the
+// arrivals board otherwise.
+func boardHandler(s *Simulator, airports *AirportStore, departures bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		iata := strings.ToUpper(r.PathValue("iata"))
+		if _, ok := airports.Airports[iata]; !ok {
+			http.Error(w, "Airport not found", http.StatusNotFound)
+			return
+		}
+		now := s.SimTime().UTC()
+		board := airportBoard{Airport: iata, SimTime: now.Format(time.RFC3339), Flights: s.board(iata, departures, now)}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(board)
+	}
+}
+
+// board lists the active flights from or to iata plus, with a timetable,
+// those due to depart within boardHorizon. Arrivals also list flights that
+// landed within landedWindow. Departures are ordered by scheduled departure
+// and arrivals by estimated arrival.
+func (s *Simulator) board(iata string, departures bool, now time.Time) []boardEntry {
+	entries := []boardEntry{}
+	flights := s.flights.snapshot()
+	if !departures {
+		flights = append(flights, s.flights.landedSince(now.Add(-compressed(landedWindow)))...)
+	}
+	for _, f := range flights {
+		if (departures && f.DepartureAirport != iata) || (!departures && f.ArrivalAirport != iata) {
+			continue
+		}
+		entries = append(entries, boardEntry{
+			FlightID: f.ID, CallSign: f.CallSign, Airline: f.Airline, AircraftType: f.AircraftType,
+			Origin: f.DepartureAirport, Destination: f.ArrivalAirport,
+			ScheduledDeparture: f.ScheduledDeparture, ScheduledArrival: f.ScheduledArrival, EstimatedArrival: f.EstimatedArrival,
+			Status: activeStatus(&f),
+		})
+	}
+	if tt := s.flights.timetable; tt != nil {
+		for _, d := range tt.due(now, now.Add(boardHorizon)) {
+			if (departures && d.Origin != iata) || (!departures && d.Destination != iata) {
+				continue
+			}
+			e := boardEntry{
+				CallSign: d.FlightNumber, Airline: airlineName(d.Airline), AircraftType: d.AircraftType,
+				Origin: d.Origin, Destination: d.Destination,
+				ScheduledDeparture: d.std.Format(time.RFC3339), Status: statusScheduled,
+			}
+			// Not routed yet, so estimated over the great circle
+			ac, _ := data.LookupAircraftType(d.AircraftType)
+			from, to := s.airports.Positions[d.Origin], s.airports.Positions[d.Destination]
+			distance := geo.CalculateDistance(from, to)
+			cruise := cruiseLevel(distance, geo.CalculateBearing(from, to), ac)
+			e.EstimatedArrival = d.std.Add(blockTime(distance, cruise, ac)).Format(time.RFC3339)
+			if sta, ok := d.sta(distance, cruise); ok {
+				e.ScheduledArrival = sta.Format(time.RFC3339)
+			}
+			if d.std.Sub(now) <= boardingWindow {
+				e.Status = statusBoarding
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	key := func(e boardEntry) time.Time {
+		if departures {
+			return parseTime(e.ScheduledDeparture)
+		}
+		return parseTime(e.EstimatedArrival)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return key(entries[i]).Before(key(entries[j])) })
+	return entries
+}
+
+func activeStatus(f *flight.State) flightStatus {
+	status := statusEnRoute
+	switch f.Phase {
+	case flight.Takeoff, flight.Climb:
+		status = statusDeparted
+	case flight.Holding:
+		status = statusHolding
+	case flight.Landed:
+		return statusLanded
+	}
+	sta, eta := parseTime(f.ScheduledArrival), parseTime(f.EstimatedArrival)
+	if !sta.IsZero() && !eta.IsZero() && eta.Sub(sta) > compressed(delayThreshold) {
+		return statusDelayed
+	}
+	return status
+}
+
+// compressed is the sim time that flights take to fly what real aircraft
+// fly in d.
+func compressed(d time.Duration) time.Duration {
+	return time.Duration(float64(d) / data.SpeedCompression)
+}
+
+// arrivalLog is a ring buffer of flights that have landed and left the
+// store, oldest overwritten first, so the arrivals boards can still list
+// them for a while.
+type arrivalLog struct {
+	flights []landedFlight
+	next    int
+}
+
+type landedFlight struct {
+	state flight.State
+	at    time.Time
+}
+
+func (l *arrivalLog) record(f *flight.State, at time.Time) {
+	entry := landedFlight{state: *f, at: at}
+	if len(l.flights) < recentArrivals {
+		l.flights = append(l.flights, entry)
+		return
+	}
+	l.flights[l.next] = entry
+	l.next = (l.next + 1) % recentArrivals
+}
+
+// landedSince copies the flights that left the store after t.
+func (s *flightStore) landedSince(t time.Time) []flight.State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []flight.State
+	for _, l := range s.arrivals.flights {
+		if l.at.After(t) {
+			out = append(out, l.state)
+		}
+	}
+	return out
+}
+
+// parseTime reads an RFC3339 field of flight.State, or the zero time.
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
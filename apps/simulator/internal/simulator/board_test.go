@@ -0,0 +1,81 @@
+package simulator
+
+import "testing"
+
+// boardRow finds flight id on iata's departures or arrivals board.
+func boardRow(s *Simulator, iata string, departures bool, id string) (boardEntry, bool) {
+	for _, e := range s.board(iata, departures, s.SimTime()) {
+		if e.FlightID == id {
+			return e, true
+		}
+	}
+	return boardEntry{}, false
+}
+
+func TestBoardShowsLateFlightDelayed(t *testing.T) {
+	// Timetabled at 9 minutes for a 7 hour flight, so late from the start
+	tt := testTimetable(t, "AAL100,AAL,JFK,LHR,00:01,00:10,1234567,B77W")
+	s, _ := newTestSimulator(t, 1, WithTimetable(tt), WithWeather(0), WithCapacity(CapacityConfig{}))
+	if err := s.Step(6 * 61); err != nil {
+		t.Fatal(err)
+	}
+	flights := s.flights.snapshot()
+	if len(flights) != 1 {
+		t.Fatalf("got %d flights, want 1", len(flights))
+	}
+	id := flights[0].ID
+	if err := s.Step(6 * 60); err != nil {
+		t.Fatal(err)
+	}
+	e, ok := boardRow(s, "LHR", false, id)
+	if !ok || e.Status != statusDelayed {
+		t.Fatalf("arrivals row %+v, %v; want %s", e, ok, statusDelayed)
+	}
+	if late := parseTime(e.EstimatedArrival).Sub(parseTime(e.ScheduledArrival)); late > delayThreshold {
+		t.Errorf("delayed by %v, which would not have shown unscaled either", late)
+	}
+}
+
+func TestBoardKeepsLandedFlight(t *testing.T) {
+	tt := testTimetable(t, "AAL3055,AAL,BOS,JFK,00:01,,1234567,A321")
+	s, _ := newTestSimulator(t, 1, WithTimetable(tt), WithWeather(0), WithCapacity(CapacityConfig{}))
+	if err := s.Step(6 * 61); err != nil {
+		t.Fatal(err)
+	}
+	flights := s.flights.snapshot()
+	if len(flights) != 1 {
+		t.Fatalf("got %d flights, want 1", len(flights))
+	}
+	id := flights[0].ID
+	if e, ok := boardRow(s, "JFK", false, id); !ok || e.Status != statusDeparted {
+		t.Fatalf("arrivals row %+v, %v; want %s", e, ok, statusDeparted)
+	}
+	for i := 0; s.FlightCount() > 0; i++ {
+		if i == 6*3600 {
+			t.Fatal("flight has not landed after an hour")
+		}
+		if err := s.Step(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	landed := s.SimTime()
+
+	for s.SimTime().Sub(landed) < compressed(landedWindow) {
+		if e, ok := boardRow(s, "JFK", false, id); !ok || e.Status != statusLanded {
+			t.Fatalf("%v after landing, arrivals row %+v, %v; want %s",
+				s.SimTime().Sub(landed), e, ok, statusLanded)
+		}
+		if _, ok := boardRow(s, "BOS", true, id); ok {
+			t.Fatal("landed flight is still on the departures board")
+		}
+		if err := s.Step(6); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Step(6); err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := boardRow(s, "JFK", false, id); ok {
+		t.Errorf("%v after landing, arrivals row %+v is still listed", s.SimTime().Sub(landed), e)
+	}
+}
@@ -0,0 +1,232 @@
+package simulator
+
+import (
+	"math"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/data"
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+// CapacityConfig sets how many arrivals and departures each runway in use
+// handles per real hour. Speeds are time-compressed, so slots are spaced
+// SpeedCompression times closer in sim time. Zero lifts the limit.
+type CapacityConfig struct {
+	Arrivals   float64
+	Departures float64
+}
+
+var DefaultCapacityConfig = CapacityConfig{Arrivals: 30, Departures: 30}
+
+const (
+	// absorbedDelay is how late, in real time, an arrival may be given a
+	// slot without holding; speed control takes up that much in practice.
+	absorbedDelay = 30 * time.Second
+	// holdLeg is the length (nm) of the straight sides of a holding pattern.
+	holdLeg = 5.0
+	// holdRadius is the radius (nm) of its turns.
+	holdRadius = 2.5
+	// holdTurnPoints is how many points each 180° turn is flown through.
+	holdTurnPoints = 6
+)
+
+// airportQueue is the next free arrival and departure slot at an airport.
+type airportQueue struct {
+	NextArrival   time.Time `json:"nextArrival"`
+	NextDeparture time.Time `json:"nextDeparture"`
+}
+
+// slot is a flight's place in its airports' queues: when it may take off,
+// when it lands once sequenced, and the hold it flies until then.
+type slot struct {
+	Takeoff time.Time `json:"takeoff"`
+	Landing time.Time `json:"landing"`
+	Hold    *hold     `json:"hold,omitempty"`
+}
+
+// hold is a racetrack flown around Fix, the first fix of the approach,
+// with right turns. The points run once round from the fix back to it.
+// A flight leaving the hold flies straight back to the fix at the hold's
+// altitude.
+type hold struct {
+	Fix      int               `json:"fix"`
+	Points   []flight.Position `json:"points"`
+	Next     int               `json:"next"`
+	Altitude float64           `json:"altitude"`
+	Leaving  bool              `json:"leaving"`
+}
+
+// spacing is the sim time between movements at a rate per runway per real
+// hour, or 0 without a limit.
+func spacing(rate float64, runways int) time.Duration {
+	if rate <= 0 || runways <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Hour) / (rate * float64(runways)) / data.SpeedCompression)
+}
+
+func (s *flightStore) queue(code string) *airportQueue {
+	q, ok := s.queues[code]
+	if !ok {
+		q = &airportQueue{}
+		s.queues[code] = q
+	}
+	return q
+}
+
+// departureSlot is the earliest time from now a flight may take off from a.
+func (s *flightStore) departureSlot(a Airport, now time.Time) time.Time {
+	gap := spacing(s.capacity.Departures, len(activeRunways(a, s.wind)))
+	if gap == 0 {
+		return now
+	}
+	q := s.queue(a.IATA)
+	takeoff := now
+	if q.NextDeparture.After(takeoff) {
+		takeoff = q.NextDeparture
+	}
+	q.NextDeparture = takeoff.Add(gap)
+	return takeoff
+}
+
+// released reports whether f may take off. A flight waiting for its
+// departure slot sits on the runway; once released it starts its roll.
+func (s *flightStore) released(f *flight.State, now time.Time) bool {
+	if sl, ok := s.slots[f.ID]; ok && now.Before(sl.Takeoff) {
+		return false
+	}
+	if f.Speed == 0 && f.Phase == flight.Takeoff {
+		f.Speed = speedForPhase(flight.Takeoff, aircraftType(f))
+	}
+	return true
+}
+
+// sequenceArrival gives f, passing the first fix of its approach, the next
+// landing slot at its destination. If that is later than it could land, it
+// holds at the fix.
+func (s *flightStore) sequenceArrival(f *flight.State, a Airport, fix int, now time.Time) {
+	gap := spacing(s.capacity.Arrivals, len(activeRunways(a, s.wind)))
+	if gap == 0 || f.GroundSpeed <= 0 {
+		return
+	}
+	sl, ok := s.slots[f.ID]
+	if !ok {
+		sl = &slot{}
+		s.slots[f.ID] = sl
+	}
+	if !sl.Landing.IsZero() {
+		return
+	}
+	q := s.queue(a.IATA)
+	eta := now.Add(flyingTime(f.DistanceRemaining, f.GroundSpeed))
+	sl.Landing = eta
+	if q.NextArrival.After(eta) {
+		sl.Landing = q.NextArrival
+	}
+	q.NextArrival = sl.Landing.Add(gap)
+	delete(s.detours, f.ID)
+	if sl.Landing.Sub(eta).Seconds() <= absorbedDelay.Seconds()/data.SpeedCompression {
+		return
+	}
+	f.NextWaypoint = fix
+	f.NextFix = f.Waypoints[fix].Name
+	sl.Hold = newHold(f.Waypoints[fix-1].Position(), f.Waypoints[fix].Position(), f.Altitude)
+	sl.Hold.Fix = fix
+}
+
+// newHold lays a racetrack on the inbound course from prev to fix: a right
+// turn at the fix, the outbound leg, a right turn back and the inbound leg.
+func newHold(prev, fix flight.Position, altitude float64) *hold {
+	inbound := geo.CalculateBearing(prev, fix)
+	near := geo.Destination(fix, inbound+90, holdRadius)
+	far := geo.Destination(near, inbound+180, holdLeg)
+	var points []flight.Position
+	for _, turn := range []struct {
+		center flight.Position
+		from   float64
+	}{{near, inbound - 90}, {far, inbound + 90}} {
+		for i := 1; i <= holdTurnPoints; i++ {
+			points = append(points, geo.Destination(turn.center, turn.from+180*float64(i)/holdTurnPoints, holdRadius))
+		}
+	}
+	points = append(points, fix)
+	return &hold{Points: points, Altitude: altitude}
+}
+
+// target is the point of the racetrack to fly to, moving round it once
+// within reach nm of the current one, or the fix when leaving.
+func (h *hold) target(f *flight.State, reach float64) flight.Position {
+	if h.Leaving {
+		return f.Waypoints[h.Fix].Position()
+	}
+	pos := f.Position
+	reach = math.Max(reach, waypointReached)
+	for geo.CalculateDistance(pos, h.Points[h.Next]) < reach {
+		h.Next = (h.Next + 1) % len(h.Points)
+	}
+	return h.Points[h.Next]
+}
+
+// holding returns the hold f is flying, if any. The flight leaves it once
+// flying straight to the fix and on down the approach lands it in its slot,
+// and is done with it once past the fix.
+func (s *flightStore) holding(f *flight.State, now time.Time) (*hold, bool) {
+	sl, ok := s.slots[f.ID]
+	if !ok || sl.Hold == nil {
+		return nil, false
+	}
+	h := sl.Hold
+	if f.NextWaypoint > h.Fix {
+		sl.Hold = nil
+		return nil, false
+	}
+	if !h.Leaving && f.GroundSpeed > 0 {
+		remaining := geo.CalculateDistance(f.Position, f.Waypoints[h.Fix].Position()) + routeLength(f.Waypoints[h.Fix:])
+		h.Leaving = !now.Add(flyingTime(remaining, f.GroundSpeed)).Before(sl.Landing)
+	}
+	return h, true
+}
+
+// sequenced reports whether f has been given a landing slot.
+func (s *flightStore) sequenced(f *flight.State) bool {
+	sl, ok := s.slots[f.ID]
+	return ok && !sl.Landing.IsZero()
+}
+
+// estimateArrival is when f should land flying the rest of its profile, no
+// earlier than its landing slot or, before it has one, the next free slot.
+func (s *flightStore) estimateArrival(f *flight.State, now time.Time) time.Time {
+	flown := math.Max(0, routeLength(f.Waypoints)-f.DistanceRemaining)
+	eta := now.Add(timeToFly(flown, f.DistanceRemaining, f.CruiseAltitude, aircraftType(f)))
+	next := time.Time{}
+	if sl, ok := s.slots[f.ID]; ok && !sl.Landing.IsZero() {
+		next = sl.Landing
+	} else if q, ok := s.queues[f.ArrivalAirport]; ok {
+		next = q.NextArrival
+	}
+	if next.After(eta) {
+		return next
+	}
+	return eta
+}
+
+// flyingTime is the sim time to cover distance nm at groundSpeed.
+func flyingTime(distance, groundSpeed float64) time.Duration {
+	return time.Duration(distance / geo.EffectiveSpeed(groundSpeed) * float64(time.Hour))
+}
+
+// approachFix is the index of the first fix of f's approach procedure, or
+// -1 for flights without an arrival runway.
+func approachFix(f *flight.State) int {
+	if f.ArrivalRunway == "" {
+		return -1
+	}
+	for i := 1; i < len(f.Waypoints)-1; i++ {
+		switch f.Waypoints[i].Name {
+		case "DW" + f.ArrivalRunway, "CI" + f.ArrivalRunway, "FF" + f.ArrivalRunway:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package simulator
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxTimeScale is the fastest the clock runs. Flights are integrated in
+// steps of at most maxStepSeconds, so at 6 Hz one tick at 60x is ten steps
+// of every flight; much beyond that a tick no longer fits in its interval.
+const MaxTimeScale = 60.0
+
+type Clock interface {
+	Now() time.Time
+}
+
+// SimClock is driven by Start one tick at a time and runs at Scale times
+// real speed; a scale of 0 pauses the simulation.
+type SimClock struct {
+	mu          sync.RWMutex
+	now         time.Time
+	scale       float64
+	resumeScale float64
+}
+
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start, scale: 1, resumeScale: 1}
+}
+
+func (c *SimClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+func (c *SimClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(time.Duration(float64(d) * c.scale))
+}
+
+func (c *SimClock) Scale() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scale
+}
+
+func (c *SimClock) SetScale(scale float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scale = scale
+	if scale > 0 {
+		c.resumeScale = scale
+	}
+}
+
+func (c *SimClock) Pause() {
+	c.SetScale(0)
+}
+
+func (c *SimClock) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scale = c.resumeScale
+}
+
+// Set moves the clock without scaling, for single steps and seeks.
+func (c *SimClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// advancer is implemented by clocks that Start must drive one tick at a time.
+type advancer interface {
+	Advance(d time.Duration)
+}
@@ -0,0 +1,261 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/data"
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+	"github.com/hannan/voyager/simulator/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ConflictConfig sets the separation minima and how far ahead, in sim time,
+// conflicts are predicted. Speeds are time-compressed about 60x, and
+// doubled again at cruise, so the default 5s look-ahead is five to ten
+// minutes of real flight. A zero LateralNM turns detection off.
+type ConflictConfig struct {
+	LateralNM  float64
+	VerticalFt float64
+	LookAhead  time.Duration
+}
+
+var DefaultConflictConfig = ConflictConfig{LateralNM: 5, VerticalFt: 1000, LookAhead: 5 * time.Second}
+
+type conflictStatus string
+
+const (
+	conflictPredicted conflictStatus = "predicted"
+	conflictActive    conflictStatus = "active"
+	conflictResolved  conflictStatus = "resolved"
+)
+
+// conflict is a pair of flights that are, or within the look-ahead will be,
+// closer than both separation minima at once.
+type conflict struct {
+	A, B       *flight.State
+	Status     conflictStatus
+	TimeToLoss time.Duration
+	LateralNM  float64
+	VerticalFt float64
+}
+
+type conflictMessage struct {
+	Type    string         `json:"type"`
+	ID      string         `json:"id"`
+	Status  conflictStatus `json:"status"`
+	Flights [2]string      `json:"flights"`
+	SimTime int64          `json:"simTime"`
+	// Seconds of sim time until separation is lost; 0 once active.
+	TimeToLoss float64 `json:"timeToLoss"`
+	// Current separation.
+	LateralNM  float64 `json:"lateralNm"`
+	VerticalFt float64 `json:"verticalFt"`
+	// Midpoint of the pair, [lon, lat].
+	Position [2]float64 `json:"position"`
+}
+
+func conflictID(a, b string) string {
+	return a + "+" + b
+}
+
+// checkConflicts runs detection and tells subscribed clients about conflicts
+// that started, changed status or cleared since the last check. It must be
+// called with tickMu held.
+func (s *Simulator) checkConflicts() {
+	if s.conflictConfig.LateralNM <= 0 {
+		return
+	}
+	simTime := s.clock.Now()
+	s.flights.mu.RLock()
+	found := detectConflicts(s.flights.grid, s.flights.flights, s.conflictConfig)
+	current := make(map[string]conflictMessage, len(found))
+	for _, c := range found {
+		current[conflictID(c.A.ID, c.B.ID)] = newConflictMessage(c, simTime)
+	}
+	s.flights.mu.RUnlock()
+
+	var events []conflictMessage
+	for id, msg := range current {
+		prev, ok := s.conflicts[id]
+		if ok && prev.Status == msg.Status {
+			continue
+		}
+		if !ok && telemetry.SeparationConflicts != nil {
+			telemetry.SeparationConflicts.Add(context.Background(), 1, metric.WithAttributes(
+				attribute.String("status", string(msg.Status))))
+		}
+		events = append(events, msg)
+	}
+	for id, prev := range s.conflicts {
+		if _, ok := current[id]; !ok {
+			prev.Status, prev.SimTime, prev.TimeToLoss = conflictResolved, simTime.UnixMilli(), 0
+			events = append(events, prev)
+		}
+	}
+	s.conflicts = current
+
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		lon, lat := e.Position[0], e.Position[1]
+		s.clients.sendEvent(eventConflicts, func(vp viewport) []byte {
+			if !vp.contains(lon, lat) {
+				return nil
+			}
+			return data
+		})
+	}
+}
+
+func newConflictMessage(c conflict, simTime time.Time) conflictMessage {
+	// Average through unit vectors so pairs straddling the antimeridian
+	// don't land on the far side of the globe
+	a, b := c.A.Position, c.B.Position
+	ax, ay := math.Cos(a.Longitude*math.Pi/180), math.Sin(a.Longitude*math.Pi/180)
+	bx, by := math.Cos(b.Longitude*math.Pi/180), math.Sin(b.Longitude*math.Pi/180)
+	return conflictMessage{
+		Type: "conflict", ID: conflictID(c.A.ID, c.B.ID), Status: c.Status,
+		Flights: [2]string{c.A.ID, c.B.ID}, SimTime: simTime.UnixMilli(),
+		TimeToLoss: c.TimeToLoss.Seconds(), LateralNM: c.LateralNM, VerticalFt: c.VerticalFt,
+		Position: [2]float64{math.Atan2(ay+by, ax+bx) * 180 / math.Pi, (a.Latitude + b.Latitude) / 2},
+	}
+}
+
+// detectConflicts checks each flight against the others the grid puts
+// within reach during the look-ahead. Flights still near the ground are left
+// out: departures from the same airport would always conflict.
+func detectConflicts(grid *flightGrid, flights map[string]*flight.State, cfg ConflictConfig) []conflict {
+	horizon := cfg.LookAhead.Seconds()
+	motions := make(map[string]motion, len(flights))
+	fastest := 0.0
+	for id, f := range flights {
+		if separated(f) {
+			motions[id] = flightMotion(f)
+			fastest = math.Max(fastest, motions[id].speed)
+		}
+	}
+	var result []conflict
+	for _, id := range sortedIDs(flights) {
+		a := flights[id]
+		ma, ok := motions[id]
+		if !ok {
+			continue
+		}
+		reach := cfg.LateralNM + (ma.speed+fastest)*horizon
+		for _, n := range grid.within(a.Position.Longitude, a.Position.Latitude, reach) {
+			b := n.Flight
+			mb, ok := motions[b.ID]
+			if b.ID <= a.ID || !ok {
+				continue
+			}
+			if c, ok := predictConflict(a, b, ma, mb, cfg); ok {
+				result = append(result, c)
+			}
+		}
+	}
+	return result
+}
+
+// motion is how far a flight moves per second of sim time as the tick
+// actually flies it: at its effective ground speed, and up or down its
+// vertical profile rather than at its real-world climb rate.
+type motion struct {
+	vx, vy float64 // nm/s east and north
+	vz     float64 // ft/s
+	speed  float64 // nm/s
+}
+
+func flightMotion(f *flight.State) motion {
+	speed := geo.EffectiveSpeed(f.GroundSpeed) / 3600
+	rad := f.Bearing * math.Pi / 180
+	m := motion{vx: speed * math.Sin(rad), vy: speed * math.Cos(rad), speed: speed}
+	if f.Phase == flight.Holding {
+		return m
+	}
+	ac := aircraftType(f)
+	flown := math.Max(0, routeLength(f.Waypoints)-f.DistanceRemaining)
+	ahead := math.Min(speed, f.DistanceRemaining)
+	m.vz = profileAltitude(f.CruiseAltitude, flown+ahead, f.DistanceRemaining-ahead, ac) -
+		profileAltitude(f.CruiseAltitude, flown, f.DistanceRemaining, ac)
+	return m
+}
+
+func separated(f *flight.State) bool {
+	return f.Phase != flight.Landed && f.Position.Altitude >= data.TakeoffAltitude
+}
+
+// predictConflict extrapolates both flights along their current motion and
+// finds when, within the look-ahead, they are inside both minima.
+func predictConflict(a, b *flight.State, ma, mb motion, cfg ConflictConfig) (conflict, bool) {
+	// Relative position in a local flat frame around a, in nm
+	meanLat := (a.Position.Latitude + b.Position.Latitude) / 2 * math.Pi / 180
+	rx := normalizeLongitude(b.Position.Longitude-a.Position.Longitude) * 60 * math.Cos(meanLat)
+	ry := (b.Position.Latitude - a.Position.Latitude) * 60
+	// Relative velocity in nm and ft per sim second
+	vx, vy := mb.vx-ma.vx, mb.vy-ma.vy
+	dz := b.Position.Altitude - a.Position.Altitude
+	vz := mb.vz - ma.vz
+
+	lo, hi := 0.0, cfg.LookAhead.Seconds()
+	lat0, lat1, ok := lateralWindow(rx, ry, vx, vy, cfg.LateralNM)
+	if !ok {
+		return conflict{}, false
+	}
+	vert0, vert1, ok := verticalWindow(dz, vz, cfg.VerticalFt)
+	if !ok {
+		return conflict{}, false
+	}
+	lo, hi = math.Max(lo, math.Max(lat0, vert0)), math.Min(hi, math.Min(lat1, vert1))
+	if lo > hi {
+		return conflict{}, false
+	}
+	c := conflict{
+		A: a, B: b, Status: conflictPredicted,
+		TimeToLoss: time.Duration(lo * float64(time.Second)),
+		LateralNM:  math.Hypot(rx, ry), VerticalFt: math.Abs(dz),
+	}
+	if lo == 0 {
+		c.Status = conflictActive
+	}
+	return c, true
+}
+
+// lateralWindow solves |r + v·t| < minimum for t in seconds.
+func lateralWindow(rx, ry, vx, vy, minimum float64) (float64, float64, bool) {
+	a := vx*vx + vy*vy
+	b := 2 * (rx*vx + ry*vy)
+	c := rx*rx + ry*ry - minimum*minimum
+	if a < 1e-9 {
+		if c < 0 {
+			return math.Inf(-1), math.Inf(1), true
+		}
+		return 0, 0, false
+	}
+	disc := b*b - 4*a*c
+	if disc <= 0 {
+		return 0, 0, false
+	}
+	sq := math.Sqrt(disc)
+	return (-b - sq) / (2 * a), (-b + sq) / (2 * a), true
+}
+
+// verticalWindow solves |dz + vz·t| < minimum for t in seconds.
+func verticalWindow(dz, vz, minimum float64) (float64, float64, bool) {
+	if math.Abs(vz) < 1e-9 {
+		if math.Abs(dz) < minimum {
+			return math.Inf(-1), math.Inf(1), true
+		}
+		return 0, 0, false
+	}
+	t0, t1 := (-minimum-dz)/vz, (minimum-dz)/vz
+	return math.Min(t0, t1), math.Max(t0, t1), true
+}
@@ -0,0 +1,86 @@
+package simulator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/data"
+	"github.com/hannan/voyager/simulator/internal/flight"
+)
+
+// equatorFlight is a B738 flying along the equator from fromLon to toLon,
+// currently at lon.
+func equatorFlight(id string, fromLon, toLon, lon, altitude, cruise, groundSpeed float64) *flight.State {
+	bearing := 90.0
+	if toLon < fromLon {
+		bearing = -90
+	}
+	return &flight.State{
+		ID: id, AircraftType: "B738", Phase: flight.Cruise,
+		Position:       flight.Position{Longitude: lon, Altitude: altitude},
+		Altitude:       altitude,
+		CruiseAltitude: cruise,
+		GroundSpeed:    groundSpeed,
+		Bearing:        bearing,
+		Waypoints: []flight.Waypoint{
+			{Name: "FROM", Longitude: fromLon},
+			{Name: "TO", Longitude: toLon},
+		},
+		DistanceRemaining: math.Abs(toLon-lon) * 60,
+	}
+}
+
+func detect(flights ...*flight.State) []conflict {
+	grid := newFlightGrid()
+	byID := make(map[string]*flight.State, len(flights))
+	for _, f := range flights {
+		grid.move(f)
+		byID[f.ID] = f
+	}
+	return detectConflicts(grid, byID, DefaultConflictConfig)
+}
+
+func TestConvergingFlightsConflict(t *testing.T) {
+	// Head-on at FL350, 100 nm apart. Each moves 2 × 30000 kt of
+	// compressed ground speed, so they close at about 33 nm per sim second
+	// and are inside 5 nm after (100-5)/33.3 s.
+	a := equatorFlight("A", -40, 40, -50.0/60, 35000, 35000, data.SpeedCruise)
+	b := equatorFlight("B", 40, -40, 50.0/60, 35000, 35000, data.SpeedCruise)
+	found := detect(a, b)
+	if len(found) != 1 {
+		t.Fatalf("got %d conflicts, want 1", len(found))
+	}
+	want := time.Duration(95.0 / (2 * 2 * data.SpeedCruise / 3600) * float64(time.Second))
+	if c := found[0]; c.Status != conflictPredicted || (c.TimeToLoss-want).Abs() > 50*time.Millisecond {
+		t.Errorf("got %s in %v, want predicted in %v", c.Status, c.TimeToLoss, want)
+	}
+
+	b.Position.Altitude, b.Altitude, b.CruiseAltitude = 37000, 37000, 37000
+	if found := detect(a, b); len(found) != 0 {
+		t.Errorf("flights 2000 ft apart conflict: %+v", found[0])
+	}
+}
+
+func TestClimbingFlightConflict(t *testing.T) {
+	// A climbs up its profile towards B, level above it and coming the
+	// other way. A gains climbGradient ft per nm flown, at twice its
+	// compressed ground speed, and reaches B's level as they pass.
+	ac, _ := data.LookupAircraftType("B738")
+	speed := 15000.0
+	perSecond := 2 * speed / 3600
+	climb := climbGradient(ac) * perSecond
+
+	a := equatorFlight("A", 0, 80, 10.0/60, 10*climbGradient(ac), 41000, speed)
+	level := a.Altitude + 3*climb
+	gap := (5 + 3*2*perSecond) / 60
+	b := equatorFlight("B", 80, -80, a.Position.Longitude+gap, level, level, speed)
+
+	found := detect(a, b)
+	if len(found) != 1 {
+		t.Fatalf("got %d conflicts, want 1", len(found))
+	}
+	if c := found[0]; c.Status != conflictPredicted || (c.TimeToLoss-3*time.Second).Abs() > 50*time.Millisecond {
+		t.Errorf("got %s in %v, want predicted in 3s", c.Status, c.TimeToLoss)
+	}
+}
@@ -0,0 +1,131 @@
+package simulator
+
+import (
+	"math"
+
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+// A delta carries only what changed since the frame numbered BaseSeq. A
+// client whose last frame is not BaseSeq has missed one and sends "resync";
+// any flights_geojson frame replaces its state outright.
+type flightsDeltaMessage struct {
+	Type            string        `json:"type"`
+	Seq             int64         `json:"seq"`
+	BaseSeq         int64         `json:"baseSeq"`
+	ServerTimestamp int64         `json:"serverTimestamp"`
+	SimTime         int64         `json:"simTime"`
+	TimeScale       float64       `json:"timeScale"`
+	Added           []geo.Feature `json:"added,omitempty"`
+	Changed         []flightDelta `json:"changed,omitempty"`
+	Removed         []string      `json:"removed,omitempty"`
+}
+
+type flightDelta struct {
+	ID          string                 `json:"id"`
+	Coordinates []float64              `json:"coordinates,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+}
+
+// deltaPrecision is how many decimals a changing number keeps in a delta;
+// negative values round to tens. Comparing rounded values keeps changes too
+// small to see on a map out of the stream.
+var deltaPrecision = map[string]int{
+	"bearing":           0,
+	"speed":             0,
+	"track":             0,
+	"heading":           0,
+	"trueAirspeed":      0,
+	"groundSpeed":       0,
+	"verticalSpeed":     -1,
+	"cruiseAltitude":    0,
+	"progress":          3,
+	"distanceRemaining": 0,
+}
+
+// coordinatePrecision is about 10 m for lon/lat and a foot for altitude.
+var coordinatePrecision = [3]int{4, 4, 0}
+
+// Deltas leave out fields the client can fill in itself: altitude is
+// coordinates[2], lastComputedAt is the frame's simTime and traceID is only
+// useful on a full frame.
+var deltaSkipped = map[string]bool{
+	"altitude":       true,
+	"lastComputedAt": true,
+	"traceID":        true,
+}
+
+// diffFlights compares two ID-sorted collections, as built by
+// buildFlightsGeoJSON, and fills in msg's added, changed and removed lists.
+func diffFlights(prev, cur []geo.Feature, msg *flightsDeltaMessage) {
+	i, j := 0, 0
+	for i < len(prev) || j < len(cur) {
+		switch {
+		case j == len(cur) || (i < len(prev) && featureID(prev[i]) < featureID(cur[j])):
+			msg.Removed = append(msg.Removed, featureID(prev[i]))
+			i++
+		case i == len(prev) || featureID(cur[j]) < featureID(prev[i]):
+			msg.Added = append(msg.Added, cur[j])
+			j++
+		default:
+			if d, ok := diffFeature(prev[i], cur[j]); ok {
+				msg.Changed = append(msg.Changed, d)
+			}
+			i++
+			j++
+		}
+	}
+}
+
+func diffFeature(prev, cur geo.Feature) (flightDelta, bool) {
+	d := flightDelta{ID: featureID(cur)}
+	pc, _ := prev.Geometry.Coordinates.([]float64)
+	cc, _ := cur.Geometry.Coordinates.([]float64)
+	for k := range cc {
+		if k >= len(pc) || k >= len(coordinatePrecision) || round(pc[k], coordinatePrecision[k]) != round(cc[k], coordinatePrecision[k]) {
+			d.Coordinates = quantizeCoordinates(cc)
+			break
+		}
+	}
+	for key, value := range cur.Properties {
+		if deltaSkipped[key] {
+			continue
+		}
+		if digits, ok := deltaPrecision[key]; ok {
+			pv, _ := prev.Properties[key].(float64)
+			cv, _ := value.(float64)
+			if round(pv, digits) == round(cv, digits) {
+				continue
+			}
+			value = round(cv, digits)
+		} else if prev.Properties[key] == value {
+			continue
+		}
+		if d.Properties == nil {
+			d.Properties = make(map[string]interface{})
+		}
+		d.Properties[key] = value
+	}
+	return d, d.Coordinates != nil || d.Properties != nil
+}
+
+func featureID(f geo.Feature) string {
+	id, _ := f.Properties["id"].(string)
+	return id
+}
+
+func quantizeCoordinates(coords []float64) []float64 {
+	out := make([]float64, len(coords))
+	for k, v := range coords {
+		if k < len(coordinatePrecision) {
+			v = round(v, coordinatePrecision[k])
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func round(v float64, digits int) float64 {
+	scale := math.Pow10(digits)
+	return math.Round(v*scale) / scale
+}
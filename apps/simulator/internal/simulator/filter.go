@@ -0,0 +1,112 @@
+package simulator
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+type circle struct {
+	Longitude, Latitude float64
+	// Nautical miles.
+	Radius float64
+}
+
+// flightFilter selects flights for API queries. Zero fields match everything.
+type flightFilter struct {
+	Airline      string
+	Airport      string
+	Departure    string
+	Arrival      string
+	AircraftType string
+	Phases       map[flight.Phase]bool
+	Bounds       *viewport
+	Within       *circle
+	// Feet, inclusive.
+	MinAltitude *float64
+	MaxAltitude *float64
+}
+
+func (q flightFilter) match(f *flight.State) bool {
+	switch {
+	case q.Airline != "" && !strings.EqualFold(f.Airline, q.Airline):
+		return false
+	case q.Airport != "" && !strings.EqualFold(f.DepartureAirport, q.Airport) && !strings.EqualFold(f.ArrivalAirport, q.Airport):
+		return false
+	case q.Departure != "" && !strings.EqualFold(f.DepartureAirport, q.Departure):
+		return false
+	case q.Arrival != "" && !strings.EqualFold(f.ArrivalAirport, q.Arrival):
+		return false
+	case q.AircraftType != "" && !strings.EqualFold(f.AircraftType, q.AircraftType):
+		return false
+	case len(q.Phases) > 0 && !q.Phases[f.Phase]:
+		return false
+	case q.Bounds != nil && !q.Bounds.contains(f.Position.Longitude, f.Position.Latitude):
+		return false
+	case q.Within != nil && geo.CalculateDistance(flight.Position{Longitude: q.Within.Longitude, Latitude: q.Within.Latitude}, f.Position) > q.Within.Radius:
+		return false
+	case q.MinAltitude != nil && f.Position.Altitude < *q.MinAltitude:
+		return false
+	case q.MaxAltitude != nil && f.Position.Altitude > *q.MaxAltitude:
+		return false
+	}
+	return true
+}
+
+func parsePhase(name string) (flight.Phase, error) {
+	switch p := flight.Phase(strings.ToLower(strings.TrimSpace(name))); p {
+	case flight.Takeoff, flight.Climb, flight.Cruise, flight.Descent, flight.Landing, flight.Landed, flight.Holding:
+		return p, nil
+	}
+	return "", errors.New("unknown phase: " + name)
+}
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+var errInvalidPageToken = errors.New("invalid page token")
+
+// pageSize applies the default and cap shared by the list endpoints.
+func pageSize(n int) (int, error) {
+	switch {
+	case n < 0:
+		return 0, errors.New("page size must not be negative")
+	case n == 0:
+		return defaultPageSize, nil
+	default:
+		return min(n, maxPageSize), nil
+	}
+}
+
+// Page tokens are the last ID of the previous page, so paging stays stable
+// while flights land and spawn between requests.
+
+func encodePageToken(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodePageToken(token string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", errInvalidPageToken
+	}
+	return string(raw), nil
+}
+
+// paginate returns up to size of the ID-sorted flights after the given ID,
+// and the token for the next page if any remain.
+func paginate(flights []flight.State, after string, size int) ([]flight.State, string) {
+	start := sort.Search(len(flights), func(i int) bool { return flights[i].ID > after })
+	end := min(start+size, len(flights))
+	page := flights[start:end]
+	if end == len(flights) || len(page) == 0 {
+		return page, ""
+	}
+	return page, encodePageToken(page[len(page)-1].ID)
+}
@@ -0,0 +1,164 @@
+package simulator
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+)
+
+type flightsPage struct {
+	Flights    []flight.State `json:"flights"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// flightsHandler lists the current flights in ID order, narrowed by the
+// query (see filterFromQuery) and paged with limit and cursor.
+func flightsHandler(s *Simulator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		filter, err := filterFromQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit := 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil {
+				http.Error(w, "limit must be an integer", http.StatusBadRequest)
+				return
+			}
+		}
+		size, err := pageSize(limit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		after, err := decodePageToken(r.URL.Query().Get("cursor"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		matched := s.flights.query(filter)
+		page, next := paginate(matched, after, size)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(flightsPage{Flights: page, Total: len(matched), NextCursor: next})
+	}
+}
+
+func flightHandler(s *Simulator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		f, ok := s.flights.lookup(r.PathValue("id"))
+		if !ok {
+			http.Error(w, "Flight not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(f)
+	}
+}
+
+// filterFromQuery reads airline, airport, origin, destination, aircraftType,
+// phase (comma-separated), bbox=w,s,e,n, lat, lon and radius (nm), and
+// minAltitude and maxAltitude.
+func filterFromQuery(r *http.Request) (flightFilter, error) {
+	q := r.URL.Query()
+	filter := flightFilter{
+		Airline:      q.Get("airline"),
+		Airport:      q.Get("airport"),
+		Departure:    q.Get("origin"),
+		Arrival:      q.Get("destination"),
+		AircraftType: q.Get("aircraftType"),
+	}
+	if v := q.Get("phase"); v != "" {
+		filter.Phases = make(map[flight.Phase]bool)
+		for _, name := range strings.Split(v, ",") {
+			phase, err := parsePhase(name)
+			if err != nil {
+				return filter, err
+			}
+			filter.Phases[phase] = true
+		}
+	}
+	if v := q.Get("bbox"); v != "" {
+		bbox, err := parseBBox(v)
+		if err != nil {
+			return filter, err
+		}
+		if filter.Bounds, err = boundingBox(bbox); err != nil {
+			return filter, err
+		}
+	}
+	if q.Has("lat") || q.Has("lon") || q.Has("radius") {
+		lat, err1 := strconv.ParseFloat(q.Get("lat"), 64)
+		lon, err2 := strconv.ParseFloat(q.Get("lon"), 64)
+		radius, err3 := strconv.ParseFloat(q.Get("radius"), 64)
+		if err1 != nil || err2 != nil || err3 != nil || !(lat >= -90 && lat <= 90) || !(radius > 0) || math.IsNaN(lon) || math.IsInf(lon, 0) {
+			return filter, errors.New("lat, lon and radius must be numbers, with -90 <= lat <= 90 and radius > 0")
+		}
+		filter.Within = &circle{Longitude: lon, Latitude: lat, Radius: radius}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **float64
+	}{{"minAltitude", &filter.MinAltitude}, {"maxAltitude", &filter.MaxAltitude}} {
+		if v := q.Get(p.name); v != "" {
+			alt, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return filter, errors.New(p.name + " must be a number")
+			}
+			*p.dst = &alt
+		}
+	}
+	return filter, nil
+}
+
+type nearbyFlightJSON struct {
+	flight.State
+	// Nautical miles.
+	Distance float64 `json:"distanceNm"`
+}
+
+func nearestFlightsHandler(s *Simulator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q := r.URL.Query()
+		lat, err1 := strconv.ParseFloat(q.Get("lat"), 64)
+		lon, err2 := strconv.ParseFloat(q.Get("lon"), 64)
+		if err1 != nil || err2 != nil || !(lat >= -90 && lat <= 90) || math.IsNaN(lon) || math.IsInf(lon, 0) {
+			http.Error(w, "lat and lon must be numbers, with -90 <= lat <= 90", http.StatusBadRequest)
+			return
+		}
+		k, err := countParam(r, "k", defaultNearest, maxNearest)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		found := s.flights.nearest(lon, lat, k)
+		result := make([]nearbyFlightJSON, len(found))
+		for i, n := range found {
+			result[i] = nearbyFlightJSON{State: *n.Flight, Distance: n.Distance}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(map[string]interface{}{"flights": result})
+	}
+}
@@ -0,0 +1,156 @@
+package simulator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+const (
+	// gridDegrees is the cell size of the flight grid. At 2000 flights most
+	// cells hold a handful, and a continental viewport spans a few hundred.
+	gridDegrees = 2.0
+	gridCols    = int(360 / gridDegrees)
+	gridRows    = int(180 / gridDegrees)
+
+	// nearestStartNM is the first radius tried by nearest, doubled until
+	// enough flights are found or it covers the globe.
+	nearestStartNM = 50.0
+	maxRadiusNM    = 10800.0
+)
+
+type gridCell struct{ col, row int }
+
+// flightGrid buckets flights by position so spatial queries only visit the
+// cells they overlap. flightStore keeps it in step with its map under the
+// same lock.
+type flightGrid struct {
+	cells map[gridCell]map[string]*flight.State
+	at    map[string]gridCell
+}
+
+type nearbyFlight struct {
+	Flight *flight.State
+	// Nautical miles.
+	Distance float64
+}
+
+func newFlightGrid() *flightGrid {
+	return &flightGrid{cells: make(map[gridCell]map[string]*flight.State), at: make(map[string]gridCell)}
+}
+
+func cellOf(lon, lat float64) gridCell {
+	col := int(math.Floor((normalizeLongitude(lon) + 180) / gridDegrees))
+	row := int(math.Floor((lat + 90) / gridDegrees))
+	return gridCell{col: (col%gridCols + gridCols) % gridCols, row: min(max(row, 0), gridRows-1)}
+}
+
+// move files f under the cell for its current position.
+func (g *flightGrid) move(f *flight.State) {
+	cell := cellOf(f.Position.Longitude, f.Position.Latitude)
+	if old, ok := g.at[f.ID]; ok {
+		if old == cell {
+			g.cells[cell][f.ID] = f
+			return
+		}
+		g.removeFrom(old, f.ID)
+	}
+	bucket, ok := g.cells[cell]
+	if !ok {
+		bucket = make(map[string]*flight.State)
+		g.cells[cell] = bucket
+	}
+	bucket[f.ID] = f
+	g.at[f.ID] = cell
+}
+
+func (g *flightGrid) remove(id string) {
+	if cell, ok := g.at[id]; ok {
+		g.removeFrom(cell, id)
+		delete(g.at, id)
+	}
+}
+
+func (g *flightGrid) removeFrom(cell gridCell, id string) {
+	delete(g.cells[cell], id)
+	if len(g.cells[cell]) == 0 {
+		delete(g.cells, cell)
+	}
+}
+
+// visit calls fn for every flight in the cells overlapping the box. Longitudes
+// may run past ±180 as in viewport; the caller does the exact test.
+func (g *flightGrid) visit(west, south, east, north float64, fn func(*flight.State)) {
+	if east < west {
+		east += 360
+	}
+	first, last := cellOf(west, south), cellOf(east, north)
+	cols := gridCols
+	if east-west < 360-gridDegrees {
+		cols = int(math.Floor((east-west)/gridDegrees)) + 2
+	}
+	for row := first.row; row <= last.row; row++ {
+		for i := 0; i < min(cols, gridCols); i++ {
+			for _, f := range g.cells[gridCell{col: (first.col + i) % gridCols, row: row}] {
+				fn(f)
+			}
+		}
+	}
+}
+
+// inViewport returns the flights inside vp.
+func (g *flightGrid) inViewport(vp viewport) []*flight.State {
+	var result []*flight.State
+	g.visit(vp.West, vp.South, vp.East, vp.North, func(f *flight.State) {
+		if vp.contains(f.Position.Longitude, f.Position.Latitude) {
+			result = append(result, f)
+		}
+	})
+	return result
+}
+
+// within returns the flights no more than radius nautical miles from lon,
+// lat.
+func (g *flightGrid) within(lon, lat, radius float64) []nearbyFlight {
+	dLat := radius / 60
+	south, north := lat-dLat, lat+dLat
+	west, east := -180.0, 180.0
+	if south > -90 && north < 90 {
+		// A degree of longitude shrinks with latitude; widen by the edge
+		// nearest the pole.
+		if dLon := dLat / math.Cos(math.Max(math.Abs(south), math.Abs(north))*math.Pi/180); dLon < 180 {
+			west, east = lon-dLon, lon+dLon
+		}
+	}
+	center := flight.Position{Longitude: lon, Latitude: lat}
+	var result []nearbyFlight
+	g.visit(west, math.Max(-90, south), east, math.Min(90, north), func(f *flight.State) {
+		if d := geo.CalculateDistance(center, f.Position); d <= radius {
+			result = append(result, nearbyFlight{Flight: f, Distance: d})
+		}
+	})
+	return result
+}
+
+// nearest returns up to k flights closest to lon, lat, nearest first.
+func (g *flightGrid) nearest(lon, lat float64, k int) []nearbyFlight {
+	if k <= 0 {
+		return nil
+	}
+	var found []nearbyFlight
+	for radius := nearestStartNM; ; radius *= 2 {
+		found = g.within(lon, lat, radius)
+		if len(found) >= k || radius >= maxRadiusNM {
+			break
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Distance != found[j].Distance {
+			return found[i].Distance < found[j].Distance
+		}
+		return found[i].Flight.ID < found[j].Flight.ID
+	})
+	return found[:min(k, len(found))]
+}
@@ -0,0 +1,184 @@
+package simulator
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/hannan/voyager/simulator/api/flightsv1"
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRoutePoints = 128
+	maxRoutePoints     = 4096
+)
+
+type flightService struct {
+	flightsv1.UnimplementedFlightServiceServer
+	sim      *Simulator
+	airports *AirportStore
+}
+
+// NewGRPCServer serves FlightService from api/flight_service.proto, with
+// reflection so grpcurl and similar tools work without the schema.
+func NewGRPCServer(s *Simulator, airports *AirportStore) *grpc.Server {
+	srv := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	flightsv1.RegisterFlightServiceServer(srv, &flightService{sim: s, airports: airports})
+	reflection.Register(srv)
+	return srv
+}
+
+func StartGRPCServer(port string, srv *grpc.Server) error {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	log.Printf("Starting gRPC server on :%s", port)
+	return srv.Serve(lis)
+}
+
+func (f *flightService) StreamFlights(req *flightsv1.StreamFlightsRequest, stream grpc.ServerStreamingServer[flightsv1.FlightsFrame]) error {
+	filter, err := filterFromProto(req.GetFilter(), req.GetBbox())
+	if err != nil {
+		return err
+	}
+	frames, cancel := f.sim.subscribeFrames()
+	defer cancel()
+
+	initial := &stateFrame{
+		ServerTimestamp: time.Now().UnixMilli(), SimTime: f.sim.SimTime().UnixMilli(), TimeScale: f.sim.TimeScale(),
+		Flights: f.sim.flights.snapshot(),
+	}
+	if err := stream.Send(protoFrame(initial, filter)); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case frame := <-frames:
+			if err := stream.Send(protoFrame(frame, filter)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (f *flightService) GetFlight(ctx context.Context, req *flightsv1.GetFlightRequest) (*flightsv1.Flight, error) {
+	state, ok := f.sim.flights.lookup(req.GetId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "flight %q not found", req.GetId())
+	}
+	return toProtoFlight(&state), nil
+}
+
+func (f *flightService) ListFlights(ctx context.Context, req *flightsv1.ListFlightsRequest) (*flightsv1.ListFlightsResponse, error) {
+	filter, err := filterFromProto(req.GetFilter(), req.GetBbox())
+	if err != nil {
+		return nil, err
+	}
+	size, err := pageSize(int(req.GetPageSize()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	after, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, next := paginate(f.sim.flights.query(filter), after, size)
+	resp := &flightsv1.ListFlightsResponse{Flights: make([]*flightsv1.Flight, len(page)), NextPageToken: next}
+	for i := range page {
+		resp.Flights[i] = toProtoFlight(&page[i])
+	}
+	return resp, nil
+}
+
+func (f *flightService) GetRoute(ctx context.Context, req *flightsv1.GetRouteRequest) (*flightsv1.Route, error) {
+	state, ok := f.sim.flights.lookup(req.GetId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "flight %q not found", req.GetId())
+	}
+	n := int(req.GetPoints())
+	if n <= 0 {
+		n = defaultRoutePoints
+	}
+	n = min(n, maxRoutePoints)
+	ensureRoute(&state, f.airports.Positions)
+	route := &flightsv1.Route{
+		FlightId:         state.ID,
+		DepartureAirport: state.DepartureAirport,
+		ArrivalAirport:   state.ArrivalAirport,
+		DistanceNm:       routeLength(state.Waypoints),
+	}
+	for _, c := range routeCoordinates(state.Waypoints, n) {
+		route.Path = append(route.Path, &flightsv1.Coordinate{Longitude: c[0], Latitude: c[1]})
+	}
+	for _, wp := range state.Waypoints {
+		route.Fixes = append(route.Fixes, &flightsv1.RouteFix{
+			Name:     wp.Name,
+			Airway:   wp.Airway,
+			Position: &flightsv1.Coordinate{Longitude: wp.Longitude, Latitude: wp.Latitude},
+		})
+	}
+	return route, nil
+}
+
+func protoFrame(frame *stateFrame, filter flightFilter) *flightsv1.FlightsFrame {
+	out := &flightsv1.FlightsFrame{
+		Seq:               frame.Seq,
+		ServerTimestampMs: frame.ServerTimestamp,
+		SimTimeMs:         frame.SimTime,
+		TimeScale:         frame.TimeScale,
+	}
+	for i := range frame.Flights {
+		if filter.match(&frame.Flights[i]) {
+			out.Flights = append(out.Flights, toProtoFlight(&frame.Flights[i]))
+		}
+	}
+	return out
+}
+
+func filterFromProto(pf *flightsv1.FlightFilter, bbox *flightsv1.BoundingBox) (flightFilter, error) {
+	filter := flightFilter{
+		Airline:      pf.GetAirline(),
+		Airport:      pf.GetAirport(),
+		Departure:    pf.GetDepartureAirport(),
+		Arrival:      pf.GetArrivalAirport(),
+		AircraftType: pf.GetAircraftType(),
+	}
+	for _, p := range pf.GetPhases() {
+		phase, ok := phaseFromProto(p)
+		if !ok {
+			return filter, status.Errorf(codes.InvalidArgument, "unknown phase %v", p)
+		}
+		if filter.Phases == nil {
+			filter.Phases = make(map[flight.Phase]bool)
+		}
+		filter.Phases[phase] = true
+	}
+	if bbox != nil {
+		vp, err := boundingBox([]float64{bbox.GetWest(), bbox.GetSouth(), bbox.GetEast(), bbox.GetNorth()})
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter.Bounds = vp
+	}
+	return filter, nil
+}
+
+func phaseFromProto(p flightsv1.Phase) (flight.Phase, bool) {
+	for phase, v := range protoPhases {
+		if v == p {
+			return phase, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,119 @@
+package simulator
+
+import (
+	"math"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+	"github.com/hannan/voyager/simulator/internal/nav"
+	"github.com/hannan/voyager/simulator/internal/wind"
+)
+
+const (
+	// climbOut is how far (nm) from the threshold departures hold the
+	// runway heading before turning on course.
+	climbOut = 6.0
+	// finalApproach is how far (nm) out arrivals join the final approach
+	// course, at the final approach fix.
+	finalApproach = 10.0
+	// glideSlope is a 3° glide path, in feet per nm.
+	glideSlope = 318.0
+	// baseLeg is how far (nm) to the side of the final approach course
+	// arrivals turn base, and how far beyond the final approach fix.
+	baseLeg = 5.0
+	// straightIn is the largest turn (degrees) onto the final approach
+	// course flown without a base leg; beyond twice it, a downwind leg is
+	// flown first.
+	straightIn = 60.0
+)
+
+// routeFlight plans f's route between its airports: off the active
+// departure runway, along airways where they help, and onto the final
+// approach to the active arrival runway.
+func routeFlight(f *flight.State, airports *AirportStore, network *nav.Network, field wind.Field) {
+	enroute := planWaypoints(network, f.DepartureAirport, f.ArrivalAirport, airports.Positions)
+	waypoints := enroute
+	if end, ok := departureRunway(airports.Airports[f.DepartureAirport], field, enroute[1].Position()); ok {
+		f.DepartureRunway = end.Ident
+		waypoints = append(departureProcedure(end), enroute[1:]...)
+	}
+	last := len(waypoints) - 1
+	if end, ok := arrivalRunway(airports.Airports[f.ArrivalAirport], field, waypoints[last-1].Position()); ok {
+		f.ArrivalRunway = end.Ident
+		waypoints = append(waypoints[:last:last], arrivalProcedure(end, waypoints[last-1].Position())...)
+	}
+	f.Route, f.Waypoints, f.NextWaypoint = nav.RouteString(enroute), waypoints, 1
+	f.Position = waypoints[0].Position()
+	sequenceRoute(f, 0)
+}
+
+// departureRunway picks the active runway end that turns least to reach
+// next after take-off.
+func departureRunway(a Airport, field wind.Field, next flight.Position) (RunwayEnd, bool) {
+	var best RunwayEnd
+	bestTurn, ok := 0.0, false
+	for _, end := range activeRunways(a, field) {
+		turn := math.Abs(angleBetween(end.Heading, geo.CalculateBearing(end.Position(), next)))
+		if !ok || turn < bestTurn {
+			best, bestTurn, ok = end, turn, true
+		}
+	}
+	return best, ok
+}
+
+// arrivalRunway picks the active runway end whose final approach fix is
+// nearest prev, the last fix before the approach.
+func arrivalRunway(a Airport, field wind.Field, prev flight.Position) (RunwayEnd, bool) {
+	var best RunwayEnd
+	bestDist, ok := 0.0, false
+	for _, end := range activeRunways(a, field) {
+		d := geo.CalculateDistance(prev, finalApproachFix(end))
+		if !ok || d < bestDist {
+			best, bestDist, ok = end, d, true
+		}
+	}
+	return best, ok
+}
+
+func finalApproachFix(end RunwayEnd) flight.Position {
+	return geo.Destination(end.Position(), end.Heading+180, finalApproach)
+}
+
+// departureProcedure starts at the threshold and climbs out along the
+// runway heading.
+func departureProcedure(end RunwayEnd) []flight.Waypoint {
+	return []flight.Waypoint{
+		procedureFix("RW"+end.Ident, end.Position()),
+		procedureFix("CO"+end.Ident, geo.Destination(end.Position(), end.Heading, climbOut)),
+	}
+}
+
+// arrivalProcedure lines a flight coming from prev up on final. Flights
+// turning sharply onto the final approach course first fly a base leg from
+// their side of it, and those arriving from ahead a downwind leg before
+// that.
+func arrivalProcedure(end RunwayEnd, prev flight.Position) []flight.Waypoint {
+	threshold := end.Position()
+	faf := finalApproachFix(end)
+	approach := []flight.Waypoint{procedureFix("FF"+end.Ident, faf), procedureFix("RW"+end.Ident, threshold)}
+
+	turn := math.Abs(angleBetween(geo.CalculateBearing(prev, faf), end.Heading))
+	if turn <= straightIn {
+		return approach
+	}
+	side := end.Heading - 90
+	if cross, _ := geo.CrossTrack(faf, threshold, prev); cross > 0 {
+		side = end.Heading + 90
+	}
+	base := geo.Destination(geo.Destination(faf, end.Heading+180, baseLeg), side, baseLeg)
+	approach = append([]flight.Waypoint{procedureFix("CI"+end.Ident, base)}, approach...)
+	if turn > 2*straightIn {
+		downwind := geo.Destination(threshold, side, baseLeg)
+		approach = append([]flight.Waypoint{procedureFix("DW"+end.Ident, downwind)}, approach...)
+	}
+	return approach
+}
+
+func procedureFix(name string, pos flight.Position) flight.Waypoint {
+	return flight.Waypoint{Name: name, Latitude: pos.Latitude, Longitude: pos.Longitude}
+}
@@ -0,0 +1,144 @@
+package simulator
+
+import (
+	"math"
+	mathrand "math/rand/v2"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/data"
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+// Horizontal speeds are time-compressed for the globe, so the vertical profile
+// is flown by distance: feet gained or lost per nautical mile.
+func climbGradient(t data.AircraftType) float64 {
+	return t.ClimbRate / (t.CruiseTAS * data.ClimbSpeedRatio) * 60
+}
+
+func descentGradient(t data.AircraftType) float64 {
+	return t.DescentRate / (t.CruiseTAS * data.DescentSpeedRatio) * 60
+}
+
+// selectCruiseAltitude picks the cruise level, now and then one below the
+// highest.
+func selectCruiseAltitude(distance, bearing float64, t data.AircraftType, rng *mathrand.Rand) float64 {
+	level := cruiseLevel(distance, bearing, t)
+	if level > data.MinCruiseAltitude+2000 && rng.Float64() < 0.3 {
+		level -= 2000
+	}
+	return level
+}
+
+// cruiseLevel is the highest semicircular flight level that still leaves
+// room for a cruise segment: odd thousands eastbound, even westbound.
+func cruiseLevel(distance, bearing float64, t data.AircraftType) float64 {
+	reachable := 0.8 * distance / (1/climbGradient(t) + 1/descentGradient(t))
+	ceiling := math.Min(t.ServiceCeiling, reachable)
+
+	offset := 0.0
+	if math.Mod(bearing+360, 360) >= 180 {
+		offset = 1000
+	}
+	level := math.Floor((ceiling-1000-offset)/2000)*2000 + 1000 + offset
+	return math.Max(data.MinCruiseAltitude, level)
+}
+
+// applyVerticalProfile places the top of climb and descent along f's route.
+func applyVerticalProfile(f *flight.State, cruise float64, t data.AircraftType) {
+	total := routeLength(f.Waypoints)
+	tocDist := math.Min(total, cruise/climbGradient(t))
+	todDist := math.Max(0, total-descentDistance(cruise, t))
+	f.CruiseAltitude = cruise
+	f.TopOfClimb = routePosition(f.Waypoints, tocDist)
+	f.TopOfClimb.Altitude = cruise
+	f.TopOfDescent = routePosition(f.Waypoints, todDist)
+	f.TopOfDescent.Altitude = cruise
+}
+
+// profileAltitude is the altitude a flight should be at once it has flown
+// `flown` nm with `remaining` nm to go.
+func profileAltitude(cruise, flown, remaining float64, t data.AircraftType) float64 {
+	return math.Max(0, math.Min(cruise, math.Min(flown*climbGradient(t), descentAltitude(remaining, t))))
+}
+
+// descentAltitude is the highest a flight can be remaining nm from
+// touchdown: on the glide slope over the final approach, and descending at
+// the aircraft's gradient onto it before that.
+func descentAltitude(remaining float64, t data.AircraftType) float64 {
+	if remaining <= finalApproach {
+		return remaining * glideSlope
+	}
+	return finalApproach*glideSlope + (remaining-finalApproach)*descentGradient(t)
+}
+
+// descentDistance is how far from touchdown a descent from altitude starts.
+func descentDistance(altitude float64, t data.AircraftType) float64 {
+	if altitude <= finalApproach*glideSlope {
+		return altitude / glideSlope
+	}
+	return finalApproach + (altitude-finalApproach*glideSlope)/descentGradient(t)
+}
+
+func verticalSpeed(prev, next float64, t data.AircraftType) float64 {
+	switch {
+	case next > prev+1:
+		return t.ClimbRate
+	case next < prev-1:
+		return -t.DescentRate
+	default:
+		return 0
+	}
+}
+
+// blockTime is the sim time to fly distance nm from takeoff to touchdown
+// at cruise, so it compares with slot spacing and flyingTime.
+func blockTime(distance, cruise float64, t data.AircraftType) time.Duration {
+	return timeToFly(0, distance, cruise, t)
+}
+
+// realBlockTime is a real-world gate-to-gate estimate at cruise TAS, with a
+// fixed allowance for taxi, climb and approach.
+func realBlockTime(distance float64, t data.AircraftType) time.Duration {
+	hours := distance/t.CruiseTAS + 0.5
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// timeToFly is the sim time, without wind, for a flight that has flown
+// `flown` nm of its route to cover the `remaining` nm. Each stretch of the
+// vertical profile is flown at its phase speed as the tick moves it, and
+// the last landingDistance nm at landing speed.
+func timeToFly(flown, remaining, cruise float64, t data.AircraftType) time.Duration {
+	total := flown + remaining
+	climb := climbGradient(t)
+	// Top of climb is at cruise, or where the climb meets the descent
+	meet := (finalApproach*glideSlope + (total-finalApproach)*descentGradient(t)) / (climb + descentGradient(t))
+	if total-meet < finalApproach {
+		meet = total * glideSlope / (climb + glideSlope)
+	}
+	toc := math.Min(cruise/climb, meet)
+	landing := total - math.Max(landingDistance, descentDistance(data.ApproachAltitude, t))
+
+	ends := []struct {
+		phase flight.Phase
+		at    float64
+	}{
+		{flight.Takeoff, data.TakeoffAltitude / climb},
+		{flight.Climb, toc},
+		{flight.Cruise, total - descentDistance(toc*climb, t)},
+		{flight.Descent, landing},
+		{flight.Landing, total},
+	}
+	hours, start := 0.0, 0.0
+	for i, e := range ends {
+		end := math.Min(math.Max(e.at, start), total)
+		if i < len(ends)-1 {
+			end = math.Min(end, math.Max(landing, 0))
+		}
+		if d := math.Min(end, total) - math.Max(start, flown); d > 0 {
+			hours += d / geo.EffectiveSpeed(speedForPhase(e.phase, t))
+		}
+		start = end
+	}
+	return time.Duration(hours * float64(time.Hour))
+}
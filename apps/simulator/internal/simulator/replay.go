@@ -0,0 +1,166 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/recording"
+	"github.com/hannan/voyager/simulator/internal/telemetry"
+)
+
+const replayInterval = 50 * time.Millisecond
+
+// Player serves a recording to WebSocket clients in place of the live
+// simulator. It walks the recording's timeline at Speed times real time and
+// sends the newest frame at or before the cursor.
+type Player struct {
+	reader      *recording.Reader
+	clients     *clientStore
+	mu          sync.Mutex
+	cursor      time.Time
+	speed       float64
+	resumeSpeed float64
+	next        int
+	last        []byte
+	lastSeq     int64
+}
+
+type PlayerStatus struct {
+	Position string  `json:"position"`
+	Start    string  `json:"start"`
+	End      string  `json:"end"`
+	Speed    float64 `json:"speed"`
+	Paused   bool    `json:"paused"`
+	Frame    int     `json:"frame"`
+	Frames   int     `json:"frames"`
+}
+
+func NewPlayer(reader *recording.Reader, speed float64, queue ClientQueue) *Player {
+	p := &Player{
+		reader:      reader,
+		clients:     newClientStore(queue),
+		cursor:      reader.Start(),
+		speed:       speed,
+		resumeSpeed: speed,
+	}
+	if speed <= 0 {
+		p.resumeSpeed = 1
+	}
+	return p
+}
+
+func (p *Player) Start(ctx context.Context) {
+	ticker := time.NewTicker(replayInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.advance(now.Sub(last))
+			last = now
+		}
+	}
+}
+
+func (p *Player) advance(elapsed time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.speed == 0 || p.reader.Len() == 0 {
+		return
+	}
+	p.cursor = p.cursor.Add(time.Duration(float64(elapsed) * p.speed))
+	if end := p.reader.End(); p.cursor.After(end) {
+		p.cursor = end
+	}
+	if i := p.reader.Search(p.cursor); i >= p.next {
+		p.emit(i)
+	}
+}
+
+// emit must be called with mu held.
+func (p *Player) emit(i int) {
+	frame, err := p.reader.Frame(i)
+	if err != nil {
+		telemetry.LogError("Replay frame read failed", err)
+		return
+	}
+	p.next = i + 1
+	p.last, p.lastSeq = frame.Payload, payloadSeq(frame.Payload)
+	p.clients.broadcast(p.lastSeq, frame.Payload)
+}
+
+// Seek jumps to t, clamped to the recording, and sends that frame at once so
+// a paused replay still shows the requested moment.
+func (p *Player) Seek(t time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reader.Len() == 0 {
+		return
+	}
+	if start := p.reader.Start(); t.Before(start) {
+		t = start
+	}
+	if end := p.reader.End(); t.After(end) {
+		t = end
+	}
+	p.cursor = t
+	p.emit(p.reader.Search(t))
+}
+
+func (p *Player) SetSpeed(speed float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.speed = speed
+	if speed > 0 {
+		p.resumeSpeed = speed
+	}
+}
+
+func (p *Player) Pause() {
+	p.SetSpeed(0)
+}
+
+func (p *Player) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.speed = p.resumeSpeed
+}
+
+func (p *Player) Status() PlayerStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PlayerStatus{
+		Position: p.cursor.UTC().Format(time.RFC3339Nano),
+		Start:    p.reader.Start().Format(time.RFC3339Nano),
+		End:      p.reader.End().Format(time.RFC3339Nano),
+		Speed:    p.speed,
+		Paused:   p.speed == 0,
+		Frame:    p.next - 1,
+		Frames:   p.reader.Len(),
+	}
+}
+
+func (p *Player) lastFrame() (int64, []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastSeq, p.last
+}
+
+func (p *Player) sendInitial(c *client) {
+	seq, data := p.lastFrame()
+	p.clients.sendInitial(c, seq, data)
+}
+
+// payloadSeq reads the seq a recorded message was broadcast with.
+func payloadSeq(payload []byte) int64 {
+	var head struct {
+		Seq int64 `json:"seq"`
+	}
+	json.Unmarshal(payload, &head)
+	return head.Seq
+}
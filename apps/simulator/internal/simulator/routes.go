@@ -0,0 +1,149 @@
+package simulator
+
+import (
+	"math"
+	mathrand "math/rand/v2"
+
+	"github.com/hannan/voyager/simulator/internal/data"
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+const (
+	hubOriginShare   = 0.6
+	minRouteDistance = 100.0 // nm
+	rangeReserve     = 1.1
+	routeAttempts    = 5
+)
+
+type route struct {
+	airline  data.Airline
+	dep, arr string
+	aircraft data.AircraftType
+}
+
+// planRoute builds a route the way an airline network looks: mostly out of the
+// airline's hubs, weighted toward big airports and domestic pairs, and never
+// longer than the aircraft can fly.
+func planRoute(airports *AirportStore, rng *mathrand.Rand) (route, bool) {
+	airline := data.Airlines[rng.IntN(len(data.Airlines))]
+	for i := 0; i < routeAttempts; i++ {
+		ac := selectAircraftType(rng)
+		dep := selectOrigin(airline, airports, rng)
+		if dep == "" {
+			return route{}, false
+		}
+		if arr := selectDestination(airline, ac, dep, airports, rng); arr != "" {
+			return route{airline: airline, dep: dep, arr: arr, aircraft: ac}, true
+		}
+	}
+	return route{}, false
+}
+
+func selectAircraftType(rng *mathrand.Rand) data.AircraftType {
+	weights := make([]float64, len(data.AircraftTypes))
+	for i, t := range data.AircraftTypes {
+		weights[i] = data.CategoryWeights[t.Category]
+	}
+	if i := weightedChoice(weights, rng); i >= 0 {
+		return data.AircraftTypes[i]
+	}
+	return data.AircraftTypes[rng.IntN(len(data.AircraftTypes))]
+}
+
+func selectOrigin(airline data.Airline, airports *AirportStore, rng *mathrand.Rand) string {
+	hubs := make([]string, 0, len(airline.Hubs))
+	for _, h := range airline.Hubs {
+		if _, ok := airports.Airports[h]; ok {
+			hubs = append(hubs, h)
+		}
+	}
+	if len(hubs) > 0 && rng.Float64() < hubOriginShare {
+		return hubs[rng.IntN(len(hubs))]
+	}
+
+	weights := make([]float64, len(airports.Codes))
+	for i, code := range airports.Codes {
+		a := airports.Airports[code]
+		weights[i] = airportWeight(a)
+		if a.Country == airline.Country {
+			weights[i] *= 3
+		}
+	}
+	if i := weightedChoice(weights, rng); i >= 0 {
+		return airports.Codes[i]
+	}
+	return ""
+}
+
+// selectDestination favours airports closer than about half the aircraft's
+// range, so turboprops fly regional hops and widebodies fly long-haul.
+func selectDestination(airline data.Airline, ac data.AircraftType, dep string, airports *AirportStore, rng *mathrand.Rand) string {
+	origin := airports.Airports[dep]
+	fromHub := isHub(airline, dep)
+	decay := ac.Range / 2
+
+	weights := make([]float64, len(airports.Codes))
+	for i, code := range airports.Codes {
+		if code == dep {
+			continue
+		}
+		a := airports.Airports[code]
+		dist := geo.CalculateDistance(origin.Position, a.Position)
+		if dist < minRouteDistance || dist*rangeReserve > ac.Range {
+			continue
+		}
+		w := airportWeight(a) * math.Exp(-dist/decay)
+		if a.Country == origin.Country {
+			w *= 3
+		}
+		if !fromHub && isHub(airline, code) {
+			w *= 8
+		}
+		weights[i] = w
+	}
+	if i := weightedChoice(weights, rng); i >= 0 {
+		return airports.Codes[i]
+	}
+	return ""
+}
+
+func airportWeight(a Airport) float64 {
+	if w, ok := data.AirportTypeWeights[a.Type]; ok {
+		return w
+	}
+	return data.AirportTypeWeights["small_airport"]
+}
+
+func isHub(airline data.Airline, code string) bool {
+	for _, h := range airline.Hubs {
+		if h == code {
+			return true
+		}
+	}
+	return false
+}
+
+// weightedChoice returns an index drawn in proportion to weights, or -1 when
+// every weight is zero.
+func weightedChoice(weights []float64, rng *mathrand.Rand) int {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return -1
+	}
+	r := rng.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	for i := len(weights) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,213 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+	"github.com/hannan/voyager/simulator/internal/wind"
+)
+
+const (
+	// Runways shorter than this (ft) are left out; airliners can't use them.
+	minRunwayLength = 4000.0
+	// syntheticRunwayLength is used for airports without runway data.
+	syntheticRunwayLength = 10000.0
+	// calmWind is the headwind (real kt) below which an airport keeps its
+	// first listed runway direction rather than turn into the wind.
+	calmWind = 5.0
+	// activeSpread is how far, in degrees, a runway end may face from the
+	// one best into the wind and still be in use, so parallels share traffic.
+	activeSpread = 30.0
+)
+
+// RunwayEnd is one direction of a runway: its designator, the threshold
+// aircraft land at and take off from, and the true heading they fly.
+type RunwayEnd struct {
+	Ident     string  `json:"ident"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Heading   float64 `json:"heading"`
+}
+
+func (e RunwayEnd) Position() flight.Position {
+	return flight.Position{Latitude: e.Latitude, Longitude: e.Longitude}
+}
+
+// Runway is a strip usable in either direction. Length is in feet.
+type Runway struct {
+	Ends      [2]RunwayEnd `json:"ends"`
+	Length    float64      `json:"length"`
+	Synthetic bool         `json:"synthetic,omitempty"`
+}
+
+var runwayColumns = []string{
+	"airport_ident", "length_ft", "closed",
+	"le_ident", "le_latitude_deg", "le_longitude_deg", "le_heading_degT",
+	"he_ident", "he_latitude_deg", "he_longitude_deg", "he_heading_degT",
+}
+
+// LoadRunways reads runways in the OurAirports runways.csv format and gives
+// them to the airports whose ICAO code matches airport_ident. Closed and
+// short runways, and those without both thresholds, are skipped. Airports
+// not in the file keep the single runway Load made up for them. It returns
+// how many airports got runways from the file.
+func (s *AirportStore) LoadRunways(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	byICAO, err := readRunwaysCSV(file)
+	if err != nil {
+		return 0, fmt.Errorf("runways %s: %w", path, err)
+	}
+	loaded := 0
+	for _, code := range s.Codes {
+		a := s.Airports[code]
+		if runways, ok := byICAO[a.ICAO]; ok && a.ICAO != "" {
+			a.Runways = runways
+			s.Airports[code] = a
+			loaded++
+		}
+	}
+	return loaded, nil
+}
+
+func readRunwaysCSV(r io.Reader) (map[string][]Runway, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[name] = i
+	}
+	for _, name := range runwayColumns {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	byICAO := make(map[string][]Runway)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return byICAO, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i := col[name]; i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		number := func(name string) (float64, bool) {
+			v, err := strconv.ParseFloat(field(name), 64)
+			return v, err == nil
+		}
+		length, _ := number("length_ft")
+		if field("closed") == "1" || length < minRunwayLength {
+			continue
+		}
+		var rwy Runway
+		ok := true
+		for i, prefix := range []string{"le_", "he_"} {
+			lat, okLat := number(prefix + "latitude_deg")
+			lon, okLon := number(prefix + "longitude_deg")
+			ok = ok && okLat && okLon
+			rwy.Ends[i] = RunwayEnd{Ident: field(prefix + "ident"), Latitude: lat, Longitude: lon}
+		}
+		if !ok {
+			continue
+		}
+		for i, prefix := range []string{"le_", "he_"} {
+			heading, ok := number(prefix + "heading_degT")
+			if !ok {
+				heading = geo.CalculateBearing(rwy.Ends[i].Position(), rwy.Ends[1-i].Position())
+			}
+			rwy.Ends[i].Heading = math.Mod(heading+360, 360)
+		}
+		rwy.Length = length
+		ident := field("airport_ident")
+		byICAO[ident] = append(byICAO[ident], rwy)
+	}
+}
+
+// syntheticRunway lays a runway through an airport's reference point on a
+// heading taken from its code, so airports without runway data still give
+// flights a line to take off and land along.
+func syntheticRunway(code string, pos flight.Position) Runway {
+	h := fnv.New32a()
+	h.Write([]byte(code))
+	heading := float64(h.Sum32()%18) * 10
+	half := syntheticRunwayLength / 2 / 6076.12
+	low := geo.Destination(pos, heading+180, half)
+	high := geo.Destination(pos, heading, half)
+	return Runway{
+		Ends: [2]RunwayEnd{
+			{Ident: runwayIdent(heading), Latitude: low.Latitude, Longitude: low.Longitude, Heading: heading},
+			{Ident: runwayIdent(heading + 180), Latitude: high.Latitude, Longitude: high.Longitude, Heading: math.Mod(heading+180, 360)},
+		},
+		Length:    syntheticRunwayLength,
+		Synthetic: true,
+	}
+}
+
+// runwayIdent is the designator for a heading: tens of degrees, 01 to 36.
+func runwayIdent(heading float64) string {
+	n := int(math.Round(math.Mod(heading+360, 360)/10)) % 36
+	if n == 0 {
+		n = 36
+	}
+	return fmt.Sprintf("%02d", n)
+}
+
+// activeRunways returns the runway ends in use at a: the one facing most
+// into the surface wind, and any others within activeSpread of it. In calm
+// air the first listed end is used.
+func activeRunways(a Airport, field wind.Field) []RunwayEnd {
+	if len(a.Runways) == 0 {
+		return nil
+	}
+	u, v := field.At(a.Position.Longitude, a.Position.Latitude, 0)
+	best, bestWind := a.Runways[0].Ends[0], calmWind
+	for _, rwy := range a.Runways {
+		for _, end := range rwy.Ends {
+			if w := headwind(end.Heading, u, v); w > bestWind {
+				best, bestWind = end, w
+			}
+		}
+	}
+	var active []RunwayEnd
+	for _, rwy := range a.Runways {
+		for _, end := range rwy.Ends {
+			if math.Abs(angleBetween(end.Heading, best.Heading)) <= activeSpread {
+				active = append(active, end)
+			}
+		}
+	}
+	return active
+}
+
+// headwind is the wind component, in the wind's units, blowing against
+// an aircraft on heading.
+func headwind(heading, u, v float64) float64 {
+	rad := heading * math.Pi / 180
+	return -(u*math.Sin(rad) + v*math.Cos(rad))
+}
+
+// angleBetween is the signed turn, -180 to 180 degrees, from a to b.
+func angleBetween(a, b float64) float64 {
+	return math.Mod(math.Mod(b-a, 360)+540, 360) - 180
+}
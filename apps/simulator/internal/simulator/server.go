@@ -1,12 +1,17 @@
 package simulator
 
 import (
+	"compress/flate"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/hannan/voyager/simulator/api"
 	"github.com/hannan/voyager/simulator/internal/geo"
 	"github.com/hannan/voyager/simulator/internal/telemetry"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -24,8 +29,43 @@ func NewRouter(s *Simulator, airports *AirportStore) http.Handler {
 	mux.HandleFunc("/healthz", healthzHandler)
 	mux.HandleFunc("/readyz", readyzHandler)
 	mux.HandleFunc("/ws/flights", s.wsFlightsHandler)
+	mux.HandleFunc("/sse/flights", func(w http.ResponseWriter, r *http.Request) {
+		serveFlightsSSE(w, r, s.clients, s.sendInitial)
+	})
+	mux.HandleFunc("/flights", flightsHandler(s))
+	mux.HandleFunc("/flights/nearest", nearestFlightsHandler(s))
+	mux.HandleFunc("/flights/{id}", flightHandler(s))
+	mux.HandleFunc("/airports/search", airportSearchHandler(airports))
+	mux.HandleFunc("/airports/nearest", airportNearestHandler(airports))
+	mux.HandleFunc("/airports/{iata}", airportHandler(s, airports))
+	mux.HandleFunc("/airports/{iata}/departures", boardHandler(s, airports, true))
+	mux.HandleFunc("/airports/{iata}/arrivals", boardHandler(s, airports, false))
 	mux.HandleFunc("/geojson/airports", airportsHandler(airports))
 	mux.HandleFunc("/geojson/flights/route", flightRouteHandler(s, airports))
+	mux.HandleFunc("/geojson/flights/track", flightTrackHandler(s))
+	mux.HandleFunc("/geojson/weather", weatherHandler(s))
+	mux.HandleFunc("/admin/clock", clockHandler(s))
+	mux.HandleFunc("/admin/snapshot", snapshotHandler(s))
+	mux.HandleFunc("/admin/clients", clientsHandler(s.clients))
+	mux.HandleFunc("/api/flights.proto", schemaHandler(api.FlightsProto))
+	mux.HandleFunc("/api/flight_service.proto", schemaHandler(api.FlightServiceProto))
+	return otelhttp.NewHandler(corsMiddleware(mux), "flight-simulator")
+}
+
+// NewReplayRouter serves a recording on the same /ws/flights path the live
+// simulator uses, plus /admin/replay to steer playback.
+func NewReplayRouter(p *Player) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/readyz", readyzHandler)
+	mux.HandleFunc("/ws/flights", func(w http.ResponseWriter, r *http.Request) {
+		serveFlightsSocket(w, r, &replayUpgrader, p.clients, p.sendInitial)
+	})
+	mux.HandleFunc("/sse/flights", func(w http.ResponseWriter, r *http.Request) {
+		serveFlightsSSE(w, r, p.clients, p.sendInitial)
+	})
+	mux.HandleFunc("/admin/replay", replayHandler(p))
+	mux.HandleFunc("/admin/clients", clientsHandler(p.clients))
 	return otelhttp.NewHandler(corsMiddleware(mux), "flight-simulator")
 }
 
@@ -44,6 +84,17 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func schemaHandler(schema []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(schema)
+	}
+}
+
 func healthzHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
@@ -92,54 +143,386 @@ func flightRouteHandler(s *Simulator, airports *AirportStore) http.HandlerFunc {
 				n = parsed
 			}
 		}
-		f, exists := s.flights.get(flightID)
+		f, exists := s.flights.lookup(flightID)
 		if !exists {
 			http.Error(w, "Flight not found", http.StatusNotFound)
 			return
 		}
-		fromPos, toPos := airports.Positions[f.DepartureAirport], airports.Positions[f.ArrivalAirport]
-		coords := geo.GenerateGreatCircleCoordinates(fromPos, toPos, n)
+		ensureRoute(&f, airports.Positions)
+		features := []geo.Feature{geo.NewLineStringFeature(routeCoordinates(f.Waypoints, n), map[string]interface{}{
+			"id": f.ID, "callSign": f.CallSign, "from": f.DepartureAirport, "to": f.ArrivalAirport,
+			"route": f.Route, "distance": routeLength(f.Waypoints),
+		})}
+		for i, wp := range f.Waypoints {
+			features = append(features, geo.NewPointFeature(wp.Longitude, wp.Latitude, 0, map[string]interface{}{
+				"id": f.ID, "name": wp.Name, "airway": wp.Airway, "sequence": i,
+			}))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		json.NewEncoder(w).Encode(geo.NewFeatureCollection(features))
+	}
+}
+
+// flightTrackHandler returns where a flight has been as a LineString ending
+// at its current position. Per-vertex values are arrays in the properties,
+// aligned with the coordinates: altitudes (ft), groundSpeeds (kt) and simTimes
+// (unix ms).
+func flightTrackHandler(s *Simulator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		flightID := r.URL.Query().Get("id")
+		if flightID == "" {
+			http.Error(w, "Missing required parameter: id", http.StatusBadRequest)
+			return
+		}
+		points, f, exists := s.flights.track(flightID)
+		if !exists {
+			http.Error(w, "Flight not found", http.StatusNotFound)
+			return
+		}
+		if n := len(points); n == 0 || points[n-1].Longitude != f.Position.Longitude || points[n-1].Latitude != f.Position.Latitude {
+			points = append(points, trackPoint{
+				Longitude: f.Position.Longitude, Latitude: f.Position.Latitude, Altitude: f.Position.Altitude,
+				GroundSpeed: f.GroundSpeed, Time: s.SimTime(),
+			})
+		}
+		coords := make([][]float64, len(points))
+		altitudes, speeds, times := make([]float64, len(points)), make([]float64, len(points)), make([]int64, len(points))
+		for i, p := range points {
+			coords[i] = []float64{p.Longitude, p.Latitude, p.Altitude}
+			altitudes[i], speeds[i], times[i] = p.Altitude, p.GroundSpeed, p.Time.UnixMilli()
+		}
 		feature := geo.NewLineStringFeature(coords, map[string]interface{}{
 			"id": f.ID, "callSign": f.CallSign, "from": f.DepartureAirport, "to": f.ArrivalAirport,
+			"altitudes": altitudes, "groundSpeeds": speeds, "simTimes": times,
 		})
 		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Header().Set("Cache-Control", "no-store")
 		json.NewEncoder(w).Encode(geo.NewFeatureCollection([]geo.Feature{feature}))
 	}
 }
 
+const maxClockSteps = 1000
+
+type clockState struct {
+	SimTime   string  `json:"simTime"`
+	TimeScale float64 `json:"timeScale"`
+	Paused    bool    `json:"paused"`
+}
+
+type clockCommand struct {
+	Action string  `json:"action"`
+	Scale  float64 `json:"scale"`
+	Steps  int     `json:"steps"`
+	Time   string  `json:"time"`
+}
+
+// clockHandler reports the sim clock on GET and applies one of pause, resume,
+// step, scale or seek on POST.
+func clockHandler(s *Simulator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var cmd clockCommand
+			if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := applyClockCommand(s, cmd); err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, ErrClockNotControllable) {
+					status = http.StatusConflict
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+			telemetry.LogInfo("Sim clock updated", "action", cmd.Action)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		scale := s.TimeScale()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(clockState{
+			SimTime: s.SimTime().UTC().Format(time.RFC3339Nano), TimeScale: scale, Paused: scale == 0,
+		})
+	}
+}
+
+func applyClockCommand(s *Simulator, cmd clockCommand) error {
+	switch cmd.Action {
+	case "pause":
+		return s.Pause()
+	case "resume":
+		return s.Resume()
+	case "scale":
+		return s.SetTimeScale(cmd.Scale)
+	case "step":
+		steps := cmd.Steps
+		if steps <= 0 {
+			steps = 1
+		}
+		if steps > maxClockSteps {
+			return errors.New("steps must be at most " + strconv.Itoa(maxClockSteps))
+		}
+		return s.Step(steps)
+	case "seek":
+		t, err := time.Parse(time.RFC3339, cmd.Time)
+		if err != nil {
+			return errors.New("time must be RFC3339")
+		}
+		return s.Seek(t)
+	default:
+		return errors.New("action must be one of pause, resume, step, scale, seek")
+	}
+}
+
+// snapshotHandler returns the current snapshot on GET and writes it to the
+// configured snapshot file on POST.
+func snapshotHandler(s *Simulator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			snap, err := s.Snapshot()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-store")
+			json.NewEncoder(w).Encode(snap)
+		case http.MethodPost:
+			if s.snapshotPath == "" {
+				http.Error(w, "No snapshot path configured", http.StatusConflict)
+				return
+			}
+			if err := s.SaveSnapshot(s.snapshotPath); err != nil {
+				telemetry.LogError("Snapshot failed", err, "path", s.snapshotPath)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			telemetry.LogInfo("Snapshot saved", "path", s.snapshotPath)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+type replayCommand struct {
+	Action string  `json:"action"`
+	Speed  float64 `json:"speed"`
+	Time   string  `json:"time"`
+	Offset float64 `json:"offset"`
+}
+
+// replayHandler reports playback on GET and applies one of pause, resume,
+// speed or seek on POST. Seek takes an RFC3339 time or seconds from the start.
+func replayHandler(p *Player) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var cmd replayCommand
+			if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			switch cmd.Action {
+			case "pause":
+				p.Pause()
+			case "resume":
+				p.Resume()
+			case "speed":
+				if cmd.Speed < 0 || cmd.Speed > MaxTimeScale {
+					http.Error(w, "speed must be between 0 and "+strconv.FormatFloat(MaxTimeScale, 'g', -1, 64), http.StatusBadRequest)
+					return
+				}
+				p.SetSpeed(cmd.Speed)
+			case "seek":
+				t := p.reader.Start().Add(time.Duration(cmd.Offset * float64(time.Second)))
+				if cmd.Time != "" {
+					parsed, err := time.Parse(time.RFC3339, cmd.Time)
+					if err != nil {
+						http.Error(w, "time must be RFC3339", http.StatusBadRequest)
+						return
+					}
+					t = parsed
+				}
+				p.Seek(t)
+			default:
+				http.Error(w, "action must be one of pause, resume, speed, seek", http.StatusBadRequest)
+				return
+			}
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(p.Status())
+	}
+}
+
+// clientsHandler lists connected stream clients with their queue depth and
+// dropped frame count.
+func clientsHandler(clients *clientStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(clients.status())
+	}
+}
+
 var wsUpgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return r.Header.Get("Origin") == "http://localhost:3000" },
+	CheckOrigin:       func(r *http.Request) bool { return r.Header.Get("Origin") == "http://localhost:3000" },
+	EnableCompression: true,
+	Subprotocols:      []string{protoSubprotocol},
+}
+
+// replayUpgrader offers no subprotocols since recordings hold JSON frames.
+var replayUpgrader = websocket.Upgrader{
+	CheckOrigin:       wsUpgrader.CheckOrigin,
+	EnableCompression: true,
 }
 
 func (s *Simulator) wsFlightsHandler(w http.ResponseWriter, r *http.Request) {
+	serveFlightsSocket(w, r, &wsUpgrader, s.clients, s.sendInitial)
+}
+
+// serveFlightsSocket registers the connection for broadcasts and sends it the
+// current frame, for both the live simulator and replays. ?encoding=delta
+// opts into flights_delta messages and the proto subprotocol into binary
+// frames, which are always full; replays only send recorded JSON frames.
+// ?events=conflicts,weather adds conflict and weather messages as JSON text
+// frames.
+func serveFlightsSocket(w http.ResponseWriter, r *http.Request, upgrader *websocket.Upgrader, clients *clientStore, initial func(*client)) {
 	tracer := otel.Tracer("flight-simulator")
 	_, span := tracer.Start(r.Context(), "websocket.upgrade")
 	defer span.End()
 
-	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	format, err := parseEncoding(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events, err := parseEvents(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		span.RecordError(err)
 		telemetry.LogError("WebSocket upgrade failed", err, "remote_addr", r.RemoteAddr)
 		return
 	}
 	span.SetAttributes(attribute.String("websocket.status", "connected"))
-	s.clients.add(conn)
+	if conn.Subprotocol() == protoSubprotocol {
+		format = formatProto
+	}
+	// Deltas are mostly numbers that deflate well; full frames stay
+	// uncompressed to keep per-client CPU flat
+	conn.EnableWriteCompression(format == formatDelta)
+	conn.SetCompressionLevel(flate.BestSpeed)
+	c := clients.add(wsConn{conn}, format)
+	c.mu.Lock()
+	c.events = events
+	c.mu.Unlock()
 	log.Printf("WebSocket client connected")
 
-	go s.clients.sendInitial(conn, s.buildFlightsGeoJSON())
+	go initial(c)
 
 	defer func() {
-		s.clients.remove(conn)
+		clients.remove(c)
 		log.Printf("WebSocket client disconnected")
 	}()
 
 	for {
-		if _, _, err := conn.ReadMessage(); err != nil {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				telemetry.LogError("WebSocket error", err)
 			}
 			break
 		}
+		if err := handleClientMessage(c, raw); err != nil {
+			if data, err := json.Marshal(errorMessage{Type: "error", Message: err.Error()}); err == nil {
+				c.sendText(data)
+			}
+		}
+	}
+}
+
+// eventSet is the extra message types a stream client has opted into
+// alongside flight frames.
+type eventSet uint8
+
+const (
+	eventConflicts eventSet = 1 << iota
+	eventWeather
+)
+
+// parseEvents reads ?events=, a comma-separated list of extra message types.
+func parseEvents(r *http.Request) (eventSet, error) {
+	var events eventSet
+	for _, name := range strings.Split(r.URL.Query().Get("events"), ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case "conflicts":
+			events |= eventConflicts
+		case "weather":
+			events |= eventWeather
+		default:
+			return 0, errors.New("unknown event: " + name)
+		}
+	}
+	return events, nil
+}
+
+func parseEncoding(r *http.Request) (streamFormat, error) {
+	switch encoding := r.URL.Query().Get("encoding"); encoding {
+	case "", "geojson":
+		return formatGeoJSON, nil
+	case "delta":
+		return formatDelta, nil
+	default:
+		return formatGeoJSON, errors.New("unknown encoding: " + encoding)
+	}
+}
+
+// handleClientMessage applies a client's request: "subscribe" with a bbox and
+// zoom limits broadcasts to that viewport, "unsubscribe" goes back to all and
+// "resync" asks for a full frame after a gap in delta seqs.
+func handleClientMessage(c *client, raw []byte) error {
+	var msg clientMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return errors.New("invalid message")
+	}
+	switch msg.Type {
+	case "subscribe":
+		vp, err := newViewport(msg.BBox, msg.Zoom)
+		if err != nil {
+			return err
+		}
+		c.setViewport(vp)
+	case "unsubscribe":
+		c.setViewport(nil)
+	case "resync":
+		c.resync()
+	default:
+		return errors.New("unknown message type: " + msg.Type)
 	}
+	return nil
 }
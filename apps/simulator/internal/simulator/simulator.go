@@ -2,15 +2,16 @@ package simulator
 
 import (
 	"context"
-	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
-	mathrand "math/rand"
+	mathrand "math/rand/v2"
 	"os"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,6 +20,12 @@ import (
 	"github.com/hannan/voyager/simulator/internal/data"
 	"github.com/hannan/voyager/simulator/internal/flight"
 	"github.com/hannan/voyager/simulator/internal/geo"
+	"github.com/hannan/voyager/simulator/internal/nav"
+	"github.com/hannan/voyager/simulator/internal/recording"
+	"github.com/hannan/voyager/simulator/internal/telemetry"
+	"github.com/hannan/voyager/simulator/internal/wind"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // ============================================================================
@@ -28,28 +35,166 @@ import (
 type Simulator struct {
 	updateHz         int
 	geoJSONFlightsHz int
+	clock            Clock
 	flights          *flightStore
 	clients          *clientStore
 	airports         *AirportStore
-	lastBroadcast    time.Time
+	tickMu           sync.Mutex
+	ticks            int64
 	seq              int64
+	snapshotPath     string
+	recorder         *recording.Recorder
+	lastMu           sync.RWMutex
+	last             *flightsGeoJSONMessage
+	subsMu           sync.Mutex
+	subs             map[chan *stateFrame]struct{}
+	conflictConfig   ConflictConfig
+	// conflicts is the last detection result by pair, guarded by tickMu.
+	conflicts map[string]conflictMessage
 }
 
-func New(updateHz, geoJSONFlightsHz int, airports *AirportStore) *Simulator {
+// stateFrame is one broadcast as flight state, for in-process consumers
+// such as the gRPC stream.
+type stateFrame struct {
+	Seq             int64
+	ServerTimestamp int64
+	SimTime         int64
+	TimeScale       float64
+	Flights         []flight.State
+}
+
+// maxStepSeconds bounds how far a flight is integrated in one go, so time
+// acceleration and seeks still follow the route and vertical profile.
+const maxStepSeconds = 1.0
+
+// landingDistance is how far (nm) from touchdown flights slow to landing
+// speed.
+const landingDistance = 50.0
+
+// MaxSeek is how far ahead Seek may move the clock in one call.
+const MaxSeek = 24 * time.Hour
+
+// seekInterval is how far Seek moves the clock per update.
+const seekInterval = 10 * time.Second
+
+var ErrClockNotControllable = errors.New("simulator clock does not support time control")
+
+type Option func(*options)
+
+type options struct {
+	clock     Clock
+	seed      uint64
+	seeded    bool
+	timetable *Timetable
+	snapshot  string
+	recorder  *recording.Recorder
+	queue     ClientQueue
+	conflicts ConflictConfig
+	wind      wind.Field
+	weather   int
+	airways   *nav.Network
+	capacity  CapacityConfig
+}
+
+// WithClock replaces the sim clock used for ticks, timestamps and schedules.
+func WithClock(c Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// WithSeed makes every random choice (routes, airlines, trace IDs) reproducible.
+func WithSeed(seed uint64) Option {
+	return func(o *options) { o.seed, o.seeded = seed, true }
+}
+
+// WithTimetable spawns flights from a schedule instead of at random.
+func WithTimetable(t *Timetable) Option {
+	return func(o *options) { o.timetable = t }
+}
+
+// WithSnapshotPath is where SaveSnapshot writes when triggered on demand.
+func WithSnapshotPath(path string) Option {
+	return func(o *options) { o.snapshot = path }
+}
+
+// WithRecorder appends every broadcast message to a recording.
+func WithRecorder(r *recording.Recorder) Option {
+	return func(o *options) { o.recorder = r }
+}
+
+// WithClientQueue sets the per-client send queue and what to do when a client
+// cannot keep up. DefaultClientQueue applies otherwise.
+func WithClientQueue(q ClientQueue) Option {
+	return func(o *options) { o.queue = q }
+}
+
+// WithConflicts sets the separation minima and look-ahead for conflict
+// detection. DefaultConflictConfig applies otherwise.
+func WithConflicts(c ConflictConfig) Option {
+	return func(o *options) { o.conflicts = c }
+}
+
+// WithWind sets the wind flights fly through. There is none otherwise.
+func WithWind(w wind.Field) Option {
+	return func(o *options) { o.wind = w }
+}
+
+// WithWeather sets how many storm cells are kept alive; 0 turns weather
+// off. DefaultWeatherCells applies otherwise.
+func WithWeather(cells int) Option {
+	return func(o *options) { o.weather = cells }
+}
+
+// WithCapacity sets the arrival and departure rates per runway. Flights
+// wait on the runway for a departure slot and hold near the destination
+// for a landing slot. DefaultCapacityConfig applies otherwise.
+func WithCapacity(c CapacityConfig) Option {
+	return func(o *options) { o.capacity = c }
+}
+
+// WithAirways routes flights along an airway network where it offers a
+// route close to the great circle. Flights fly direct otherwise.
+func WithAirways(n *nav.Network) Option {
+	return func(o *options) { o.airways = n }
+}
+
+func New(updateHz, geoJSONFlightsHz int, airports *AirportStore, opts ...Option) *Simulator {
+	o := options{queue: DefaultClientQueue, conflicts: DefaultConflictConfig, weather: DefaultWeatherCells, capacity: DefaultCapacityConfig}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.clock == nil {
+		o.clock = NewSimClock(time.Now())
+	}
+	if !o.seeded {
+		o.seed = uint64(time.Now().UnixNano())
+	}
 	s := &Simulator{
 		updateHz:         updateHz,
 		geoJSONFlightsHz: geoJSONFlightsHz,
-		flights:          newFlightStore(),
-		clients:          newClientStore(),
+		clock:            o.clock,
+		flights:          newFlightStore(o.clock, o.seed),
+		clients:          newClientStore(o.queue),
 		airports:         airports,
-		lastBroadcast:    time.Now(),
+		snapshotPath:     o.snapshot,
+		recorder:         o.recorder,
+		conflictConfig:   o.conflicts,
+	}
+	s.flights.timetable = o.timetable
+	if o.wind != nil {
+		s.flights.wind = o.wind
+	}
+	s.flights.weather.target = max(o.weather, 0)
+	s.flights.airways = o.airways
+	s.flights.capacity = o.capacity
+	if o.timetable == nil {
+		s.flights.generateBurst(data.InitialFlights, s.airports)
 	}
-	s.flights.generateBurst(50, s.airports)
 	return s
 }
 
 func (s *Simulator) Start(ctx context.Context) {
-	ticker := time.NewTicker(time.Duration(1000/s.updateHz) * time.Millisecond)
+	interval := time.Duration(1000/s.updateHz) * time.Millisecond
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -57,47 +202,338 @@ func (s *Simulator) Start(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			s.flights.update(s.updateHz, s.airports)
-			s.broadcast()
+			if c, ok := s.clock.(advancer); ok {
+				c.Advance(interval)
+			}
+			s.Tick()
 		}
 	}
 }
 
+// Tick runs a single simulation step at the clock's current time.
+func (s *Simulator) Tick() {
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+	s.flights.update(s.airports)
+	s.ticks++
+	s.checkConflicts()
+	s.broadcast()
+	if s.ticks%int64(s.updateHz) == 0 {
+		s.publishWeather()
+	}
+}
+
+// Step moves a controllable clock forward by n tick intervals, regardless of
+// time scale, and broadcasts the result. It is meant to be used while paused.
+func (s *Simulator) Step(n int) error {
+	c, ok := s.clock.(*SimClock)
+	if !ok {
+		return ErrClockNotControllable
+	}
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+	interval := time.Second / time.Duration(s.updateHz)
+	for i := 0; i < n; i++ {
+		c.Set(c.Now().Add(interval))
+		s.flights.update(s.airports)
+	}
+	s.checkConflicts()
+	s.publish()
+	s.publishWeather()
+	return nil
+}
+
+// Seek fast-forwards the simulation to t. Flights cannot be rewound, so t
+// must not be before the current sim time, nor more than MaxSeek after it.
+func (s *Simulator) Seek(t time.Time) error {
+	c, ok := s.clock.(*SimClock)
+	if !ok {
+		return ErrClockNotControllable
+	}
+	now := c.Now()
+	if t.Before(now) {
+		return fmt.Errorf("cannot seek backwards from %s to %s", now.Format(time.RFC3339), t.Format(time.RFC3339))
+	}
+	if t.Sub(now) > MaxSeek {
+		return fmt.Errorf("cannot seek more than %s ahead", MaxSeek)
+	}
+	// Spawns happen once per update, so move in chunks rather than one jump.
+	// Each chunk takes the tick lock on its own, so the tick loop and clients
+	// reading flights are not held up for the whole seek.
+	for s.seekChunk(c, t) {
+	}
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+	s.checkConflicts()
+	s.publish()
+	s.publishWeather()
+	return nil
+}
+
+// seekChunk moves c up to seekInterval towards t, and reports whether t is
+// still ahead.
+func (s *Simulator) seekChunk(c *SimClock, t time.Time) bool {
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+	if !c.Now().Before(t) {
+		return false
+	}
+	next := c.Now().Add(seekInterval)
+	if next.After(t) {
+		next = t
+	}
+	c.Set(next)
+	s.flights.update(s.airports)
+	return true
+}
+
+func (s *Simulator) SimTime() time.Time {
+	return s.clock.Now()
+}
+
+func (s *Simulator) TimeScale() float64 {
+	if c, ok := s.clock.(*SimClock); ok {
+		return c.Scale()
+	}
+	return 1
+}
+
+func (s *Simulator) SetTimeScale(scale float64) error {
+	c, ok := s.clock.(*SimClock)
+	if !ok {
+		return ErrClockNotControllable
+	}
+	if scale < 0 || scale > MaxTimeScale || math.IsNaN(scale) {
+		return fmt.Errorf("time scale must be between 0 and %g", MaxTimeScale)
+	}
+	c.SetScale(scale)
+	return nil
+}
+
+func (s *Simulator) Pause() error {
+	return s.SetTimeScale(0)
+}
+
+func (s *Simulator) Resume() error {
+	c, ok := s.clock.(*SimClock)
+	if !ok {
+		return ErrClockNotControllable
+	}
+	c.Resume()
+	return nil
+}
+
+// broadcast is paced in ticks rather than sim time, so clients see the same
+// frame rate whether the simulation is paused or running at 60x.
 func (s *Simulator) broadcast() {
-	if s.clients.count() == 0 {
-		return
+	perBroadcast := int64(s.updateHz / s.geoJSONFlightsHz)
+	if perBroadcast < 1 {
+		perBroadcast = 1
 	}
-	now := time.Now()
-	if now.Sub(s.lastBroadcast) < time.Second/time.Duration(s.geoJSONFlightsHz) {
+	if s.ticks%perBroadcast != 0 {
 		return
 	}
-	s.lastBroadcast = now
+	s.publish()
+}
 
+func (s *Simulator) publish() {
+	now, simTime := time.Now(), s.clock.Now()
 	atomic.AddInt64(&s.seq, 1)
 	msg := flightsGeoJSONMessage{
 		Type:              "flights_geojson",
 		FeatureCollection: s.buildFlightsGeoJSON(),
 		Seq:               s.seq,
 		ServerTimestamp:   now.UnixMilli(),
+		SimTime:           simTime.UnixMilli(),
+		TimeScale:         s.TimeScale(),
+	}
+	// Kept current with nobody listening too, so the first delta client
+	// after a quiet spell starts from this frame rather than a stale one
+	s.lastMu.Lock()
+	prev := s.last
+	s.last = &msg
+	s.lastMu.Unlock()
+	s.notify(msg)
+	if s.clients.count() == 0 && s.recorder == nil {
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if s.recorder != nil {
+		if !s.recorder.Record(now, simTime, data) && telemetry.RecordingDroppedFrames != nil {
+			telemetry.RecordingDroppedFrames.Add(context.Background(), 1)
+		}
+	}
+
+	// Clients sharing a viewport share one encoded frame
+	full := map[viewport][]byte{{}: data}
+	deltas := make(map[viewport][]byte)
+	binary := make(map[viewport][]byte)
+	s.clients.broadcastWith(msg.Seq, func(c *client) []byte {
+		vp := viewportKey(c.viewport)
+		switch c.format {
+		case formatGeoJSON:
+			return encodeFrame(full, vp, msg)
+		case formatProto:
+			if cached, ok := binary[vp]; ok {
+				return cached
+			}
+			binary[vp] = encodeFlightsFrame(msg.Seq, msg.ServerTimestamp, msg.SimTime, msg.TimeScale, s.flights.inViewport(vp), vp)
+			return binary[vp]
+		}
+		if c.seq == msg.Seq {
+			return nil // sendInitial already sent this frame
+		}
+		base := c.seq
+		c.seq = msg.Seq
+		if prev == nil || base != prev.Seq {
+			return encodeFrame(full, vp, msg)
+		}
+		if cached, ok := deltas[vp]; ok {
+			return cached
+		}
+		delta := flightsDeltaMessage{
+			Type: "flights_delta", Seq: msg.Seq, BaseSeq: prev.Seq,
+			ServerTimestamp: msg.ServerTimestamp, SimTime: msg.SimTime, TimeScale: msg.TimeScale,
+		}
+		diffFlights(vp.filter(prev.FeatureCollection).Features, vp.filter(msg.FeatureCollection).Features, &delta)
+		encoded, err := json.Marshal(delta)
+		if err != nil {
+			return nil
+		}
+		deltas[vp] = encoded
+		return encoded
+	})
+}
+
+// subscribeFrames delivers each broadcast until cancel is called. The channel
+// holds one frame; a reader that falls behind gets the newest.
+func (s *Simulator) subscribeFrames() (<-chan *stateFrame, func()) {
+	ch := make(chan *stateFrame, 1)
+	s.subsMu.Lock()
+	if s.subs == nil {
+		s.subs = make(map[chan *stateFrame]struct{})
+	}
+	s.subs[ch] = struct{}{}
+	s.subsMu.Unlock()
+	return ch, func() {
+		s.subsMu.Lock()
+		delete(s.subs, ch)
+		s.subsMu.Unlock()
+	}
+}
+
+func (s *Simulator) notify(msg flightsGeoJSONMessage) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	if len(s.subs) == 0 {
+		return
+	}
+	frame := &stateFrame{
+		Seq: msg.Seq, ServerTimestamp: msg.ServerTimestamp, SimTime: msg.SimTime, TimeScale: msg.TimeScale,
+		Flights: s.flights.snapshot(),
+	}
+	for ch := range s.subs {
+		select {
+		case ch <- frame:
+			continue
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- frame:
+		default:
+		}
+	}
+}
+
+func encodeFrame(cache map[viewport][]byte, vp viewport, msg flightsGeoJSONMessage) []byte {
+	if cached, ok := cache[vp]; ok {
+		return cached
 	}
-	if data, err := json.Marshal(msg); err == nil {
-		s.clients.broadcast(data)
+	msg.FeatureCollection = vp.filter(msg.FeatureCollection)
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	cache[vp] = encoded
+	return encoded
+}
+
+// sendInitial gives a new client its first frame. Delta clients get the last
+// broadcast frame and its seq so the next delta applies on top of it.
+func (s *Simulator) sendInitial(c *client) {
+	defer s.sendInitialWeather(c)
+	switch c.format {
+	case formatGeoJSON:
+		c.mu.Lock()
+		vp := viewportKey(c.viewport)
+		c.mu.Unlock()
+		s.clients.sendInitial(c, 0, s.initialMessage(vp))
+		return
+	case formatProto:
+		c.mu.Lock()
+		data := encodeFlightsFrame(0, time.Now().UnixMilli(), s.clock.Now().UnixMilli(), s.TimeScale(), s.flights.sorted(), viewportKey(c.viewport))
+		ok := c.enqueue(outFrame{messageType: websocket.BinaryMessage, data: data})
+		c.mu.Unlock()
+		if !ok {
+			s.clients.remove(c)
+		}
+		return
+	}
+	s.lastMu.RLock()
+	last := s.last
+	s.lastMu.RUnlock()
+
+	c.mu.Lock()
+	// A broadcast may have sent the client a newer frame since s.last was read
+	if last == nil || last.Seq <= c.seq {
+		c.mu.Unlock()
+		return
+	}
+	ok := true
+	if data := encodeFrame(make(map[viewport][]byte), viewportKey(c.viewport), *last); data != nil {
+		c.seq = last.Seq
+		ok = c.enqueue(outFrame{messageType: websocket.TextMessage, seq: last.Seq, data: data})
+	}
+	c.mu.Unlock()
+	if !ok {
+		s.clients.remove(c)
 	}
 }
 
+func (s *Simulator) initialMessage(vp viewport) []byte {
+	msg := flightsGeoJSONMessage{
+		Type: "flights_geojson", FeatureCollection: vp.filter(s.buildFlightsGeoJSON()), Seq: 0,
+		ServerTimestamp: time.Now().UnixMilli(), SimTime: s.clock.Now().UnixMilli(), TimeScale: s.TimeScale(),
+	}
+	data, _ := json.Marshal(msg)
+	return data
+}
+
 func (s *Simulator) FlightCount() int {
 	return s.flights.count()
 }
 
 func (s *Simulator) buildFlightsGeoJSON() geo.FeatureCollection {
-	flights := s.flights.getAll()
+	flights := s.flights.snapshot()
 	features := make([]geo.Feature, 0, len(flights))
-	for _, f := range flights {
+	for i := range flights {
+		f := &flights[i]
 		features = append(features, geo.NewPointFeature(f.Position.Longitude, f.Position.Latitude, f.Position.Altitude, map[string]interface{}{
 			"id": f.ID, "callSign": f.CallSign, "airline": f.Airline,
+			"aircraftType": f.AircraftType, "aircraftCategory": aircraftType(f).Category,
 			"departureAirport": f.DepartureAirport, "arrivalAirport": f.ArrivalAirport,
 			"phase": string(f.Phase), "bearing": f.Bearing, "speed": f.Speed,
-			"altitude": f.Position.Altitude, "progress": f.Progress, "distanceRemaining": f.DistanceRemaining,
+			"track": f.Track, "heading": f.Heading, "trueAirspeed": f.TrueAirspeed, "groundSpeed": f.GroundSpeed,
+			"altitude": f.Position.Altitude, "verticalSpeed": f.VerticalSpeed, "cruiseAltitude": f.CruiseAltitude,
+			"progress": f.Progress, "distanceRemaining": f.DistanceRemaining, "nextFix": f.NextFix,
 			"scheduledDeparture": f.ScheduledDeparture, "scheduledArrival": f.ScheduledArrival,
 			"estimatedArrival": f.EstimatedArrival, "lastComputedAt": f.LastComputedAt, "traceID": f.TraceID,
 		}))
@@ -112,14 +548,40 @@ func (s *Simulator) buildFlightsGeoJSON() geo.FeatureCollection {
 type flightStore struct {
 	mu          sync.RWMutex
 	flights     map[string]*flight.State
+	tracks      map[string]*track
+	grid        *flightGrid
+	clock       Clock
+	rng         *mathrand.Rand
+	rngSource   *mathrand.PCG
+	timetable   *Timetable
+	wind        wind.Field
+	airways     *nav.Network
+	weather     *weatherStore
+	detours     map[string]*detour
+	capacity    CapacityConfig
+	queues      map[string]*airportQueue
+	slots       map[string]*slot
+	arrivals    *arrivalLog
 	lastTickAt  time.Time
 	lastSpawnAt time.Time
 }
 
-func newFlightStore() *flightStore {
-	now := time.Now()
+func newFlightStore(clock Clock, seed uint64) *flightStore {
+	now := clock.Now()
+	src := newRandSource(seed)
 	return &flightStore{
 		flights:     make(map[string]*flight.State),
+		tracks:      make(map[string]*track),
+		grid:        newFlightGrid(),
+		clock:       clock,
+		rng:         mathrand.New(src),
+		rngSource:   src,
+		wind:        wind.Calm{},
+		weather:     newWeatherStore(DefaultWeatherCells, seed),
+		detours:     make(map[string]*detour),
+		queues:      make(map[string]*airportQueue),
+		slots:       make(map[string]*slot),
+		arrivals:    &arrivalLog{},
 		lastTickAt:  now,
 		lastSpawnAt: now,
 	}
@@ -132,12 +594,103 @@ func (s *flightStore) get(id string) (*flight.State, bool) {
 	return f, ok
 }
 
-func (s *flightStore) getAll() map[string]*flight.State {
+// snapshot copies the flights in ID order, for readers outside the tick.
+func (s *flightStore) snapshot() []flight.State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]flight.State, 0, len(s.flights))
+	for _, id := range sortedIDs(s.flights) {
+		result = append(result, *s.flights[id])
+	}
+	return result
+}
+
+func (s *flightStore) lookup(id string) (flight.State, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.flights[id]
+	if !ok {
+		return flight.State{}, false
+	}
+	return *f, true
+}
+
+// query copies the flights matching filter in ID order, using the grid to
+// narrow radius and bounds filters.
+func (s *flightStore) query(filter flightFilter) []flight.State {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	result := make(map[string]*flight.State, len(s.flights))
-	for id, f := range s.flights {
-		result[id] = f
+	var candidates []*flight.State
+	switch {
+	case filter.Within != nil:
+		for _, n := range s.grid.within(filter.Within.Longitude, filter.Within.Latitude, filter.Within.Radius) {
+			candidates = append(candidates, n.Flight)
+		}
+	case filter.Bounds != nil && filter.Bounds.Zoom >= minViewportZoom:
+		candidates = s.grid.inViewport(*filter.Bounds)
+	default:
+		candidates = make([]*flight.State, 0, len(s.flights))
+		for _, f := range s.flights {
+			candidates = append(candidates, f)
+		}
+	}
+	result := make([]flight.State, 0, len(candidates))
+	for _, f := range candidates {
+		if filter.match(f) {
+			result = append(result, *f)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// nearest copies up to k flights closest to lon, lat, nearest first.
+func (s *flightStore) nearest(lon, lat float64, k int) []nearbyFlight {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	found := s.grid.nearest(lon, lat, k)
+	for i := range found {
+		f := *found[i].Flight
+		found[i].Flight = &f
+	}
+	return found
+}
+
+// inViewport returns the flights inside vp in ID order, like sorted.
+func (s *flightStore) inViewport(vp viewport) []*flight.State {
+	if vp.Zoom < minViewportZoom {
+		return s.sorted()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := s.grid.inViewport(vp)
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// track returns the flight's recorded positions, oldest first, along with
+// its current state.
+func (s *flightStore) track(id string) ([]trackPoint, flight.State, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.flights[id]
+	if !ok {
+		return nil, flight.State{}, false
+	}
+	var points []trackPoint
+	if t, ok := s.tracks[id]; ok {
+		points = t.list()
+	}
+	return points, *f, true
+}
+
+// sorted returns copies of the flights in ID order, matching
+// buildFlightsGeoJSON, so it is safe to call outside the tick.
+func (s *flightStore) sorted() []*flight.State {
+	flights := s.snapshot()
+	result := make([]*flight.State, len(flights))
+	for i := range flights {
+		result[i] = &flights[i]
 	}
 	return result
 }
@@ -146,6 +699,7 @@ func (s *flightStore) add(f *flight.State) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.flights[f.ID] = f
+	s.grid.move(f)
 }
 
 func (s *flightStore) count() int {
@@ -154,80 +708,162 @@ func (s *flightStore) count() int {
 	return len(s.flights)
 }
 
-func (s *flightStore) update(updateHz int, airports *AirportStore) {
-	now := time.Now()
+func (s *flightStore) update(airports *AirportStore) {
+	now := s.clock.Now()
 	dt := now.Sub(s.lastTickAt).Seconds()
-	s.lastTickAt = now
-	if dt > 5.0 {
-		dt = 1.0 / float64(updateHz)
+	if dt <= 0 {
+		// Paused
+		return
 	}
-	if dt < 0.001 {
-		dt = 0.001
+	s.lastTickAt = now
+
+	if s.timetable != nil {
+		s.scheduledSpawn(now, airports)
+	} else {
+		s.dynamicSpawn(now, airports)
 	}
 
-	s.dynamicSpawn(now, airports)
+	s.weather.update(now, dt, airports, s.wind)
+	hazards := s.weather.hazards(now)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	for dt > 0 {
+		step := math.Min(dt, maxStepSeconds)
+		s.advance(step, now, airports, hazards)
+		dt -= step
+	}
+}
+
+func (s *flightStore) advance(dt float64, now time.Time, airports *AirportStore, hazards []hazard) {
 	var toRemove []string
-	positions := airports.Positions
 
-	for id, f := range s.flights {
-		// Remove landed flights immediately
+	// Sorted so the RNG is consumed in the same order on every run
+	for _, id := range sortedIDs(s.flights) {
+		f := s.flights[id]
+		// Remove landed flights immediately, leaving them to the boards
 		if f.Phase == flight.Landed {
+			s.arrivals.record(f, now)
 			toRemove = append(toRemove, id)
 			continue
 		}
 
-		fromPos, toPos := positions[f.DepartureAirport], positions[f.ArrivalAirport]
+		ensureRoute(f, airports.Positions)
+		ac := aircraftType(f)
+		if !s.released(f, now) {
+			f.LastComputedAt = now.Format(time.RFC3339)
+			continue
+		}
+
+		totalDist := routeLength(f.Waypoints)
+		prevAlt := f.Altitude
+		reach := f.GroundSpeed * dt / 3600
 
-		f.Position = geo.GreatCircleStep(f.Position, toPos, f.Speed, dt)
-		f.Bearing = geo.CalculateBearing(f.Position, toPos)
-		f.Velocity = geo.SpeedToVelocity(f.Speed, f.Bearing)
+		h, holding := s.holding(f, now)
+		var target flight.Position
+		switch {
+		case holding:
+			target = h.target(f, reach)
+		case s.sequenced(f):
+			// On the approach, clear of weather
+			target = f.Waypoints[f.NextWaypoint].Position()
+		default:
+			target = s.routeTarget(f, f.Waypoints[f.NextWaypoint].Position(), hazards)
+		}
+		s.applyWind(f, target)
+		f.Position = geo.GreatCircleStep(f.Position, target, f.GroundSpeed, dt)
+		passed := f.NextWaypoint
+		if holding && !h.Leaving {
+			f.FixDistance = geo.CalculateDistance(f.Position, f.Waypoints[f.NextWaypoint].Position())
+			f.FixBearing = geo.CalculateBearing(f.Position, f.Waypoints[f.NextWaypoint].Position())
+		} else {
+			sequenceRoute(f, reach)
+		}
+		if _, detouring := s.detours[f.ID]; detouring || holding {
+			f.Bearing = geo.CalculateBearing(f.Position, target)
+		} else {
+			f.Bearing = f.FixBearing
+		}
+		f.Velocity = geo.SpeedToVelocity(f.GroundSpeed, f.Bearing)
+		f.DistanceRemaining = f.FixDistance + routeLength(f.Waypoints[f.NextWaypoint:])
+		if fix := approachFix(f); fix >= passed && fix < f.NextWaypoint {
+			s.sequenceArrival(f, airports.Airports[f.ArrivalAirport], fix, now)
+			h, holding = s.holding(f, now)
+		}
+		if holding {
+			f.Position.Altitude = h.Altitude
+		} else {
+			f.Position.Altitude = profileAltitude(f.CruiseAltitude, math.Max(0, totalDist-f.DistanceRemaining), f.DistanceRemaining, ac)
+		}
 		f.Altitude = f.Position.Altitude
-		f.DistanceRemaining = geo.CalculateDistance(f.Position, toPos)
+		f.VerticalSpeed = verticalSpeed(prevAlt, f.Altitude, ac)
 
-		if totalDist := geo.CalculateDistance(fromPos, toPos); totalDist > 0.1 {
+		if totalDist > 0.1 {
 			f.Progress = math.Max(0, math.Min(1, 1.0-(f.DistanceRemaining/totalDist)))
 		}
-		if f.DistanceRemaining < 50 {
-			f.Speed = data.SpeedLanding
+		if f.DistanceRemaining < landingDistance {
+			f.Speed = speedForPhase(flight.Landing, ac)
 		}
-		if f.Speed > 50 {
-			f.EstimatedArrival = now.Add(time.Duration(f.DistanceRemaining / f.Speed * float64(time.Hour))).Format(time.RFC3339)
+		if f.GroundSpeed > 50 {
+			f.EstimatedArrival = s.estimateArrival(f, now).Format(time.RFC3339)
 		}
 
-		if newPhase := calculatePhase(f); newPhase != f.Phase {
+		newPhase := calculatePhase(f)
+		if holding {
+			newPhase = flight.Holding
+		}
+		if newPhase != f.Phase {
 			f.Phase = newPhase
-			f.Speed = speedForPhase(f.Phase)
+			f.Speed = speedForPhase(f.Phase, ac)
 		}
-		if (f.DistanceRemaining < 15.0 && f.Altitude < 500 && f.Speed < 15000) || f.Progress >= 1.0 {
+		if f.DistanceRemaining < waypointReached || f.Progress >= 1.0 {
 			f.Phase, f.DistanceRemaining, f.Progress = flight.Landed, 0, 1.0
 		}
 
 		f.LastComputedAt = now.Format(time.RFC3339)
-		f.TraceID = generateTraceID()
+		f.TraceID = generateTraceID(s.rng)
+		s.grid.move(f)
+
+		t, ok := s.tracks[id]
+		if !ok {
+			t = &track{}
+			s.tracks[id] = t
+		}
+		t.record(f, now)
 	}
 
 	for _, id := range toRemove {
 		delete(s.flights, id)
+		delete(s.tracks, id)
+		delete(s.detours, id)
+		delete(s.slots, id)
+		s.grid.remove(id)
 	}
 }
 
+// applyWind sets the heading and ground speed that keep f on the great
+// circle to dest at its current airspeed.
+func (s *flightStore) applyWind(f *flight.State, dest flight.Position) {
+	u, v := s.wind.At(f.Position.Longitude, f.Position.Latitude, f.Position.Altitude)
+	f.Track = geo.CalculateBearing(f.Position, dest)
+	f.TrueAirspeed = f.Speed
+	f.Heading, f.GroundSpeed = wind.Triangle(f.Track, f.Speed, u*data.SpeedCompression, v*data.SpeedCompression)
+}
+
 func (s *flightStore) dynamicSpawn(now time.Time, airports *AirportStore) {
 	count := s.count()
-	if count >= 2200 {
+	if count >= data.MaxFlights {
 		return
 	}
 	var interval time.Duration
 	var burst int
-	if count < 2000 {
-		progress := float64(count) / 2000
+	if count < data.TargetFlights {
+		progress := float64(count) / data.TargetFlights
 		interval = time.Duration(5+15*progress) * time.Second
-		burst = int(30 - 25*progress + mathrand.Float64()*10)
+		burst = int(30 - 25*progress + s.rng.Float64()*10)
 	} else {
-		interval, burst = 30*time.Second, int(5+mathrand.Float64()*10)
+		interval, burst = 30*time.Second, int(5+s.rng.Float64()*10)
 	}
 	if now.Sub(s.lastSpawnAt) >= interval {
 		s.generateBurst(burst, airports)
@@ -236,101 +872,319 @@ func (s *flightStore) dynamicSpawn(now time.Time, airports *AirportStore) {
 }
 
 func (s *flightStore) generateBurst(count int, airports *AirportStore) {
-	codes := airports.Codes
-	if len(codes) <= 1 {
+	if len(airports.Codes) <= 1 {
 		return
 	}
+	now := s.clock.Now()
 	for i := 0; i < count; i++ {
-		dep, arr := codes[mathrand.Intn(len(codes))], codes[mathrand.Intn(len(codes))]
-		for arr == dep {
-			arr = codes[mathrand.Intn(len(codes))]
+		r, ok := planRoute(airports, s.rng)
+		if !ok {
+			continue
 		}
-		airline := data.Airlines[mathrand.Intn(len(data.Airlines))]
-		if f := createFlight(dep, arr, airline.Name, fmt.Sprintf("%s%d", airline.ICAOCode, i+1), airports.Positions); f != nil {
+		if f := s.createFlight(r.dep, r.arr, r.airline.Name, fmt.Sprintf("%s%d", r.airline.ICAOCode, i+1), r.aircraft, airports, now); f != nil {
 			s.add(f)
 		}
 	}
 }
 
-func createFlight(dep, arr, airline, callSign string, positions map[string]flight.Position) *flight.State {
+// createFlight puts a new flight on the departure runway, routed to the
+// arrival runway. It waits there for the next departure slot.
+func (s *flightStore) createFlight(dep, arr, airline, callSign string, ac data.AircraftType, airports *AirportStore, now time.Time) *flight.State {
 	if dep == arr {
 		return nil
 	}
-	fromPos, toPos := positions[dep], positions[arr]
-	fromPos.Altitude = 2000 + mathrand.Float64()*8000
-	bearing := geo.CalculateBearing(fromPos, toPos)
-	now := time.Now()
-	return &flight.State{
-		ID: fmt.Sprintf("%s-%s-%s", callSign, dep, arr), CallSign: callSign, Airline: airline,
-		DepartureAirport: dep, ArrivalAirport: arr, Phase: flight.Takeoff,
-		Position: fromPos, Velocity: geo.SpeedToVelocity(data.SpeedTakeoff, bearing),
-		Bearing: bearing, Speed: data.SpeedTakeoff, Altitude: fromPos.Altitude,
-		Progress: 0, DistanceRemaining: geo.CalculateDistance(fromPos, toPos),
-		ScheduledDeparture: now.Format(time.RFC3339),
-		ScheduledArrival:   now.Add(6 * time.Hour).Format(time.RFC3339),
-		EstimatedArrival:   now.Add(6*time.Hour + time.Duration((mathrand.Float64()-0.5)*30)*time.Minute).Format(time.RFC3339),
-		LastComputedAt:     now.Format(time.RFC3339),
-		TraceID:            generateTraceID(),
+	fromPos, toPos := airports.Positions[dep], airports.Positions[arr]
+	f := &flight.State{
+		ID: flightID(callSign, dep, arr), CallSign: callSign, Airline: airline,
+		AircraftType: ac.Code, DepartureAirport: dep, ArrivalAirport: arr, Phase: flight.Takeoff,
 	}
+	routeFlight(f, airports, s.airways, s.wind)
+	distance := routeLength(f.Waypoints)
+	speed := speedForPhase(flight.Takeoff, ac)
+	delay := time.Duration(0)
+	if takeoff := s.departureSlot(airports.Airports[dep], now); takeoff.After(now) {
+		s.slots[f.ID] = &slot{Takeoff: takeoff}
+		delay, speed = takeoff.Sub(now), 0
+	}
+	f.Velocity = geo.SpeedToVelocity(speed, f.FixBearing)
+	f.Bearing, f.Track, f.Heading = f.FixBearing, f.FixBearing, f.FixBearing
+	f.Speed, f.TrueAirspeed, f.GroundSpeed = speed, speed, speed
+	f.Altitude, f.VerticalSpeed, f.DistanceRemaining = fromPos.Altitude, ac.ClimbRate, distance
+	applyVerticalProfile(f, selectCruiseAltitude(distance, geo.CalculateBearing(fromPos, toPos), ac, s.rng), ac)
+	block := blockTime(distance, f.CruiseAltitude, ac)
+	f.ScheduledDeparture = now.Format(time.RFC3339)
+	f.ScheduledArrival = now.Add(block).Format(time.RFC3339)
+	f.EstimatedArrival = now.Add(block + delay).Format(time.RFC3339)
+	f.LastComputedAt = now.Format(time.RFC3339)
+	f.TraceID = generateTraceID(s.rng)
+	return f
+}
+
+func flightID(callSign, dep, arr string) string {
+	return fmt.Sprintf("%s-%s-%s", callSign, dep, arr)
 }
 
 func calculatePhase(f *flight.State) flight.Phase {
 	switch {
-	case f.Progress < 0.15 && f.Altitude < 15000 && f.Speed < 15000:
+	case f.VerticalSpeed > 0 && f.Altitude < data.TakeoffAltitude:
 		return flight.Takeoff
-	case f.Progress < 0.25 && f.Altitude < 40000:
+	case f.VerticalSpeed > 0:
 		return flight.Climb
-	case (f.DistanceRemaining < 50 || f.Progress > 0.90) && f.Altitude < 8000 && f.Speed < 21000:
+	case f.VerticalSpeed < 0 && f.Altitude < data.ApproachAltitude:
 		return flight.Landing
-	case f.Progress > 0.75 && (f.Altitude < 45000 || f.DistanceRemaining < 200):
+	case f.VerticalSpeed < 0:
 		return flight.Descent
+	case f.Altitude < data.TakeoffAltitude && f.Progress < 0.5:
+		return flight.Takeoff
 	default:
 		return flight.Cruise
 	}
 }
 
-func speedForPhase(phase flight.Phase) float64 {
+func speedForPhase(phase flight.Phase, ac data.AircraftType) float64 {
 	switch phase {
 	case flight.Takeoff:
-		return data.SpeedTakeoff
+		return data.SpeedTakeoff * ac.SpeedScale()
 	case flight.Climb:
-		return data.SpeedClimb
+		return data.SpeedClimb * ac.SpeedScale()
 	case flight.Cruise:
-		return data.SpeedCruise
+		return data.SpeedCruise * ac.SpeedScale()
 	case flight.Descent:
-		return data.SpeedDescent
+		return data.SpeedDescent * ac.SpeedScale()
 	case flight.Landing:
-		return data.SpeedLanding
+		return data.SpeedLanding * ac.SpeedScale()
+	case flight.Holding:
+		return data.SpeedHolding * ac.SpeedScale()
 	default:
 		return 0
 	}
 }
 
+func aircraftType(f *flight.State) data.AircraftType {
+	if t, ok := data.LookupAircraftType(f.AircraftType); ok {
+		return t
+	}
+	return data.AircraftTypes[0]
+}
+
 // ============================================================================
 // ClientStore
 // ============================================================================
 
+type streamFormat int
+
+const (
+	formatGeoJSON streamFormat = iota
+	formatDelta
+	formatProto
+)
+
+func (f streamFormat) String() string {
+	switch f {
+	case formatDelta:
+		return "delta"
+	case formatProto:
+		return "proto"
+	}
+	return "geojson"
+}
+
+// SlowClientPolicy decides what happens when a client's send queue is full.
+type SlowClientPolicy string
+
+const (
+	DropOldest SlowClientPolicy = "drop-oldest"
+	Coalesce   SlowClientPolicy = "coalesce"
+	Disconnect SlowClientPolicy = "disconnect"
+)
+
+func ParseSlowClientPolicy(s string) (SlowClientPolicy, error) {
+	switch p := SlowClientPolicy(s); p {
+	case DropOldest, Coalesce, Disconnect:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown slow client policy %q", s)
+}
+
+// ClientQueue bounds the frames waiting for each client's writer. Coalesce
+// always keeps a single frame, the newest.
+type ClientQueue struct {
+	Policy SlowClientPolicy
+	Size   int
+}
+
+var DefaultClientQueue = ClientQueue{Policy: DropOldest, Size: 4}
+
+func (q ClientQueue) capacity() int {
+	if q.Policy == Coalesce || q.Size < 1 {
+		return 1
+	}
+	return q.Size
+}
+
+// outFrame is one queued message; seq is 0 for frames outside the numbered
+// stream, such as errors and the connect-time frame.
+type outFrame struct {
+	messageType int
+	seq         int64
+	data        []byte
+}
+
+// streamConn is the transport under a client: a WebSocket or an SSE response.
+type streamConn interface {
+	writeFrame(f outFrame) error
+	Close() error
+	RemoteAddr() string
+}
+
+type wsConn struct {
+	*websocket.Conn
+}
+
+func (c wsConn) writeFrame(f outFrame) error {
+	c.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	return c.WriteMessage(f.messageType, f.data)
+}
+
+func (c wsConn) RemoteAddr() string {
+	return c.Conn.RemoteAddr().String()
+}
+
+// Only the client's writer goroutine touches the connection for writes.
+// client.mu guards the subscription and orders enqueues, so each frame is
+// encoded against exactly what the client was sent before it.
+type client struct {
+	id      int64
+	conn    streamConn
+	format  streamFormat
+	policy  SlowClientPolicy
+	send    chan outFrame
+	done    chan struct{}
+	stopped chan struct{}
+	dropped atomic.Int64
+
+	mu       sync.Mutex
+	viewport *viewport
+	seq      int64
+	events   eventSet
+}
+
+func (c *client) frameType() int {
+	if c.format == formatProto {
+		return websocket.BinaryMessage
+	}
+	return websocket.TextMessage
+}
+
+// enqueue must be called with mu held. It reports false when the policy says
+// the client should be disconnected.
+func (c *client) enqueue(f outFrame) bool {
+	select {
+	case c.send <- f:
+		return true
+	default:
+	}
+	c.recordDrop()
+	if c.policy == Disconnect {
+		return false
+	}
+	select {
+	case <-c.send:
+	default:
+	}
+	// The next delta would build on a frame the client never gets
+	c.seq = 0
+	select {
+	case c.send <- f:
+	default:
+	}
+	return true
+}
+
+func (c *client) recordDrop() {
+	c.dropped.Add(1)
+	if telemetry.WebSocketDroppedFrames != nil {
+		telemetry.WebSocketDroppedFrames.Add(context.Background(), 1, metric.WithAttributes(
+			attribute.Int64("client.id", c.id), attribute.String("policy", string(c.policy))))
+	}
+}
+
+// sendText queues a reply outside the broadcast stream, such as an error.
+func (c *client) sendText(data []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enqueue(outFrame{messageType: websocket.TextMessage, data: data})
+}
+
+// setViewport also forgets the client's last frame, since a delta against
+// the old viewport would leave flights behind.
+func (c *client) setViewport(v *viewport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.viewport, c.seq = v, 0
+}
+
+// resync makes the next broadcast a full frame.
+func (c *client) resync() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq = 0
+}
+
 type clientStore struct {
 	mu      sync.RWMutex
-	clients map[*websocket.Conn]bool
+	clients map[int64]*client
+	queue   ClientQueue
+	nextID  int64
 }
 
-func newClientStore() *clientStore {
-	return &clientStore{clients: make(map[*websocket.Conn]bool)}
+func newClientStore(queue ClientQueue) *clientStore {
+	return &clientStore{clients: make(map[int64]*client), queue: queue}
 }
 
-func (s *clientStore) add(conn *websocket.Conn) {
+func (s *clientStore) add(conn streamConn, format streamFormat) *client {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.clients[conn] = true
+	s.nextID++
+	c := &client{
+		id:      s.nextID,
+		conn:    conn,
+		format:  format,
+		policy:  s.queue.Policy,
+		send:    make(chan outFrame, s.queue.capacity()),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	s.clients[c.id] = c
+	go s.writeLoop(c)
+	return c
 }
 
-func (s *clientStore) remove(conn *websocket.Conn) {
+func (s *clientStore) remove(c *client) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, exists := s.clients[conn]; exists {
-		delete(s.clients, conn)
-		conn.Close()
+	if _, exists := s.clients[c.id]; exists {
+		delete(s.clients, c.id)
+		close(c.done)
+		c.conn.Close()
+	}
+}
+
+// writeLoop is the client's only writer, so a slow connection backs up its
+// own queue instead of the tick loop.
+func (s *clientStore) writeLoop(c *client) {
+	defer close(c.stopped)
+	for {
+		select {
+		case <-c.done:
+			return
+		case f := <-c.send:
+			if err := c.conn.writeFrame(f); err != nil {
+				log.Printf("Error broadcasting: %v", err)
+				s.remove(c)
+				return
+			}
+		}
 	}
 }
 
@@ -340,26 +1194,90 @@ func (s *clientStore) count() int {
 	return len(s.clients)
 }
 
-func (s *clientStore) sendInitial(conn *websocket.Conn, fc geo.FeatureCollection) {
-	msg := flightsGeoJSONMessage{Type: "flights_geojson", FeatureCollection: fc, Seq: 0, ServerTimestamp: time.Now().UnixMilli()}
-	if data, err := json.Marshal(msg); err == nil {
-		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-		conn.WriteMessage(websocket.TextMessage, data)
-	}
-}
-
-func (s *clientStore) broadcast(data []byte) {
+func (s *clientStore) list() []*client {
 	s.mu.RLock()
-	clients := make([]*websocket.Conn, 0, len(s.clients))
-	for c := range s.clients {
+	defer s.mu.RUnlock()
+	clients := make([]*client, 0, len(s.clients))
+	for _, c := range s.clients {
 		clients = append(clients, c)
 	}
-	s.mu.RUnlock()
+	sort.Slice(clients, func(i, j int) bool { return clients[i].id < clients[j].id })
+	return clients
+}
+
+type ClientStatus struct {
+	ID         int64  `json:"id"`
+	RemoteAddr string `json:"remoteAddr"`
+	Format     string `json:"format"`
+	Policy     string `json:"policy"`
+	Queued     int    `json:"queued"`
+	Dropped    int64  `json:"dropped"`
+}
 
+func (s *clientStore) status() []ClientStatus {
+	clients := s.list()
+	status := make([]ClientStatus, 0, len(clients))
 	for _, c := range clients {
-		c.SetWriteDeadline(time.Now().Add(5 * time.Second))
-		if err := c.WriteMessage(websocket.TextMessage, data); err != nil {
-			log.Printf("Error broadcasting: %v", err)
+		status = append(status, ClientStatus{
+			ID:         c.id,
+			RemoteAddr: c.conn.RemoteAddr(),
+			Format:     c.format.String(),
+			Policy:     string(c.policy),
+			Queued:     len(c.send),
+			Dropped:    c.dropped.Load(),
+		})
+	}
+	return status
+}
+
+func (s *clientStore) sendInitial(c *client, seq int64, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	c.mu.Lock()
+	ok := c.enqueue(outFrame{messageType: c.frameType(), seq: seq, data: data})
+	c.mu.Unlock()
+	if !ok {
+		s.remove(c)
+	}
+}
+
+func (s *clientStore) broadcast(seq int64, data []byte) {
+	s.broadcastWith(seq, func(*client) []byte { return data })
+}
+
+// broadcastWith queues frame seq for each client as render builds it; a nil
+// frame skips the client. render runs with the client's lock held and
+// nothing here waits on the network.
+func (s *clientStore) broadcastWith(seq int64, render func(*client) []byte) {
+	for _, c := range s.list() {
+		c.mu.Lock()
+		ok := true
+		if data := render(c); data != nil {
+			ok = c.enqueue(outFrame{messageType: c.frameType(), seq: seq, data: data})
+		}
+		c.mu.Unlock()
+		if !ok {
+			log.Printf("Disconnecting slow stream client %d", c.id)
+			s.remove(c)
+		}
+	}
+}
+
+// sendEvent queues a text frame for every client subscribed to kind. render
+// builds it for the client's viewport, or returns nil to skip the client.
+func (s *clientStore) sendEvent(kind eventSet, render func(viewport) []byte) {
+	for _, c := range s.list() {
+		c.mu.Lock()
+		ok := true
+		if c.events&kind != 0 {
+			if data := render(viewportKey(c.viewport)); data != nil {
+				ok = c.enqueue(outFrame{messageType: websocket.TextMessage, data: data})
+			}
+		}
+		c.mu.Unlock()
+		if !ok {
+			log.Printf("Disconnecting slow stream client %d", c.id)
 			s.remove(c)
 		}
 	}
@@ -369,16 +1287,30 @@ func (s *clientStore) broadcast(data []byte) {
 // AirportStore
 // ============================================================================
 
+type Airport struct {
+	IATA     string
+	ICAO     string
+	Name     string
+	City     string
+	Type     string
+	Country  string
+	Position flight.Position
+	Runways  []Runway
+}
+
 type AirportStore struct {
 	RawJSON   []byte
 	ETag      string
+	Airports  map[string]Airport
 	Positions map[string]flight.Position
 	Codes     []string
 	Loaded    bool
+	// index holds Codes by position.
+	index *geo.Index
 }
 
 func NewAirportStore() *AirportStore {
-	return &AirportStore{Positions: make(map[string]flight.Position)}
+	return &AirportStore{Airports: make(map[string]Airport), Positions: make(map[string]flight.Position)}
 }
 
 func (s *AirportStore) Load(path string) error {
@@ -397,14 +1329,27 @@ func (s *AirportStore) Load(path string) error {
 	if err := json.Unmarshal(raw, &geoJSONData); err != nil {
 		return err
 	}
+	s.Airports = make(map[string]Airport)
 	s.Positions = make(map[string]flight.Position)
 	s.Codes = make([]string, 0, len(geoJSONData.Features))
 	for _, f := range geoJSONData.Features {
 		if iata, ok := f.Properties["iata"].(string); ok && iata != "" && len(f.Geometry.Coordinates) >= 2 {
-			s.Positions[iata] = flight.Position{Longitude: f.Geometry.Coordinates[0], Latitude: f.Geometry.Coordinates[1]}
+			pos := flight.Position{Longitude: f.Geometry.Coordinates[0], Latitude: f.Geometry.Coordinates[1]}
+			airport := Airport{IATA: iata, Position: pos, Runways: []Runway{syntheticRunway(iata, pos)}}
+			airport.ICAO, _ = f.Properties["icao"].(string)
+			airport.Name, _ = f.Properties["name"].(string)
+			airport.City, _ = f.Properties["city"].(string)
+			airport.Type, _ = f.Properties["type"].(string)
+			airport.Country, _ = f.Properties["country"].(string)
+			s.Airports[iata] = airport
+			s.Positions[iata] = pos
 			s.Codes = append(s.Codes, iata)
 		}
 	}
+	s.index = geo.NewIndex(len(s.Codes), func(i int) (float64, float64) {
+		p := s.Positions[s.Codes[i]]
+		return p.Longitude, p.Latitude
+	})
 	s.RawJSON = raw
 	hash := sha256.Sum256(raw)
 	s.ETag = hex.EncodeToString(hash[:])
@@ -421,12 +1366,34 @@ type flightsGeoJSONMessage struct {
 	FeatureCollection geo.FeatureCollection `json:"featureCollection"`
 	Seq               int64                 `json:"seq"`
 	ServerTimestamp   int64                 `json:"serverTimestamp"`
+	SimTime           int64                 `json:"simTime"`
+	TimeScale         float64               `json:"timeScale"`
+}
+
+type clientMessage struct {
+	Type string    `json:"type"`
+	BBox []float64 `json:"bbox"`
+	Zoom float64   `json:"zoom"`
+}
+
+type errorMessage struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func newRandSource(seed uint64) *mathrand.PCG {
+	return mathrand.NewPCG(seed, seed^0x9e3779b97f4a7c15)
+}
+
+func generateTraceID(rng *mathrand.Rand) string {
+	return fmt.Sprintf("%016x%016x", rng.Uint64(), rng.Uint64())
 }
 
-func generateTraceID() string {
-	bytes := make([]byte, 16)
-	if _, err := rand.Read(bytes); err != nil {
-		return fmt.Sprintf("%016x%016x", time.Now().UnixNano(), mathrand.Int63())
+func sortedIDs(flights map[string]*flight.State) []string {
+	ids := make([]string, 0, len(flights))
+	for id := range flights {
+		ids = append(ids, id)
 	}
-	return hex.EncodeToString(bytes)
+	sort.Strings(ids)
+	return ids
 }
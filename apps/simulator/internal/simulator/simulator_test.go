@@ -0,0 +1,235 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// testAirports loads a handful of large airports on both sides of the
+// Atlantic.
+func testAirports(t *testing.T) *AirportStore {
+	t.Helper()
+	airports := []struct {
+		iata, icao, country string
+		lon, lat            float64
+	}{
+		{"JFK", "KJFK", "US", -73.7781, 40.6413},
+		{"BOS", "KBOS", "US", -71.0096, 42.3656},
+		{"ORD", "KORD", "US", -87.9073, 41.9742},
+		{"ATL", "KATL", "US", -84.4277, 33.6407},
+		{"LAX", "KLAX", "US", -118.4085, 33.9416},
+		{"SFO", "KSFO", "US", -122.3790, 37.6213},
+		{"LHR", "EGLL", "GB", -0.4543, 51.4700},
+		{"CDG", "LFPG", "FR", 2.5479, 49.0097},
+	}
+	features := make([]string, len(airports))
+	for i, a := range airports {
+		features[i] = fmt.Sprintf(`{"type":"Feature","geometry":{"type":"Point","coordinates":[%g,%g]},`+
+			`"properties":{"iata":%q,"icao":%q,"name":%q,"type":"large_airport","country":%q}}`,
+			a.lon, a.lat, a.iata, a.icao, a.iata, a.country)
+	}
+	path := filepath.Join(t.TempDir(), "airports.geojson")
+	raw := `{"type":"FeatureCollection","features":[` + strings.Join(features, ",") + `]}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewAirportStore()
+	if err := store.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func newTestSimulator(t *testing.T, seed uint64, opts ...Option) (*Simulator, *SimClock) {
+	t.Helper()
+	clock := NewSimClock(testStart)
+	clock.Pause()
+	opts = append([]Option{WithClock(clock), WithSeed(seed)}, opts...)
+	return New(6, 2, testAirports(t), opts...), clock
+}
+
+func TestSameSeedSameFlights(t *testing.T) {
+	run := func(seed uint64) []byte {
+		s, _ := newTestSimulator(t, seed)
+		for _, n := range []int{1, 5, 60, 600} {
+			if err := s.Step(n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		snap, err := s.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := json.Marshal(snap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	first := run(42)
+	if !bytes.Equal(first, run(42)) {
+		t.Fatal("two runs with the same seed and ticks differ")
+	}
+	if bytes.Equal(first, run(43)) {
+		t.Fatal("runs with different seeds are identical")
+	}
+}
+
+func TestSeek(t *testing.T) {
+	s, clock := newTestSimulator(t, 1)
+	target := testStart.Add(95 * time.Second)
+	if err := s.Seek(target); err != nil {
+		t.Fatal(err)
+	}
+	if !clock.Now().Equal(target) {
+		t.Fatalf("clock at %v after seek, want %v", clock.Now(), target)
+	}
+	if err := s.Seek(testStart); err == nil {
+		t.Error("seeking backwards succeeded")
+	}
+	if err := s.Seek(target.Add(MaxSeek + time.Second)); err == nil {
+		t.Error("seeking past MaxSeek succeeded")
+	}
+}
+
+func TestLastFrameKeptWithoutClients(t *testing.T) {
+	s, _ := newTestSimulator(t, 1)
+	for i := 0; i < 3; i++ {
+		if err := s.Step(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.lastMu.RLock()
+	last := s.last
+	s.lastMu.RUnlock()
+	if last == nil || last.Seq != s.seq {
+		t.Fatalf("last frame is stale with no clients connected: %+v, seq %d", last, s.seq)
+	}
+	if got, want := len(last.FeatureCollection.Features), s.FlightCount(); got != want {
+		t.Errorf("last frame has %d flights, want %d", got, want)
+	}
+}
+
+func TestRestoreCarriesOn(t *testing.T) {
+	capacity := WithCapacity(CapacityConfig{Arrivals: 2, Departures: 2})
+	snapshot := func(s *Simulator) ([]byte, Snapshot) {
+		snap, err := s.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := json.Marshal(snap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw, snap
+	}
+
+	original, _ := newTestSimulator(t, 7, capacity)
+	if err := original.Step(600); err != nil {
+		t.Fatal(err)
+	}
+	saved, snap := snapshot(original)
+	if len(snap.Slots) == 0 || len(snap.Queues) == 0 || len(snap.Tracks) == 0 {
+		t.Fatalf("snapshot has %d slots, %d queues and %d tracks; want some of each",
+			len(snap.Slots), len(snap.Queues), len(snap.Tracks))
+	}
+	var decoded Snapshot
+	if err := json.Unmarshal(saved, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	restored, _ := newTestSimulator(t, 8, capacity)
+	if err := restored.Restore(decoded); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := snapshot(restored); !bytes.Equal(raw, saved) {
+		t.Fatal("restored snapshot differs from the saved one")
+	}
+
+	for _, s := range []*Simulator{original, restored} {
+		if err := s.Step(600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, _ := snapshot(original)
+	if got, _ := snapshot(restored); !bytes.Equal(got, want) {
+		t.Fatal("restored simulation diverged from the original")
+	}
+}
+
+func TestRestoreWithoutWeather(t *testing.T) {
+	original, _ := newTestSimulator(t, 7)
+	if err := original.Step(60); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := original.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Weather) == 0 {
+		t.Fatal("snapshot has no weather cells")
+	}
+	restored, _ := newTestSimulator(t, 7, WithWeather(0))
+	if err := restored.Restore(snap); err != nil {
+		t.Fatal(err)
+	}
+	if cells := restored.flights.weather.list(); len(cells) != 0 {
+		t.Errorf("restored %d weather cells with weather off", len(cells))
+	}
+}
+
+func TestSendInitialKeepsNewerDelta(t *testing.T) {
+	s, _ := newTestSimulator(t, 1)
+	if err := s.Step(1); err != nil {
+		t.Fatal(err)
+	}
+	newer := s.seq + 1
+	c := &client{format: formatDelta, send: make(chan outFrame, 1), seq: newer}
+	s.sendInitial(c)
+	if len(c.send) != 0 || c.seq != newer {
+		t.Fatalf("client on seq %d was sent the older frame %d", newer, s.seq)
+	}
+
+	c = &client{format: formatDelta, send: make(chan outFrame, 1)}
+	s.sendInitial(c)
+	if len(c.send) != 1 || c.seq != s.seq {
+		t.Fatalf("new client is on seq %d with %d frames queued, want the last frame %d", c.seq, len(c.send), s.seq)
+	}
+}
+
+// steadyWind blows the same everywhere, in knots towards the east and north.
+type steadyWind struct{ u, v float64 }
+
+func (w steadyWind) At(lon, lat, altitude float64) (float64, float64) { return w.u, w.v }
+
+func TestTrackRecordsGroundSpeed(t *testing.T) {
+	s, _ := newTestSimulator(t, 1, WithWind(steadyWind{u: 100}))
+	checked := 0
+	for i := 0; i < 6*4; i++ {
+		if err := s.Step(1); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range s.flights.snapshot() {
+			points, g, _ := s.flights.track(f.ID)
+			if n := len(points); n == 0 || !points[n-1].Time.Equal(s.SimTime()) {
+				continue
+			}
+			if got := points[len(points)-1].GroundSpeed; got != g.GroundSpeed {
+				t.Fatalf("%s track has %g kt, want its ground speed %g (airspeed %g)", f.ID, got, g.GroundSpeed, g.Speed)
+			}
+			if g.GroundSpeed != g.Speed {
+				checked++
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no flight was recorded flying in the wind")
+	}
+}
@@ -0,0 +1,191 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+)
+
+// snapshotVersion is bumped whenever Snapshot or the flight state in it
+// changes shape; older snapshots are refused rather than half-restored.
+const snapshotVersion = 4
+
+// Snapshot is everything needed to carry a running simulation across a
+// restart: flights and what the store keeps beside them, spawn timers, the
+// broadcast sequence and the RNG state.
+type Snapshot struct {
+	Version     int            `json:"version"`
+	SimTime     time.Time      `json:"simTime"`
+	TimeScale   float64        `json:"timeScale"`
+	Seq         int64          `json:"seq"`
+	Ticks       int64          `json:"ticks"`
+	LastTickAt  time.Time      `json:"lastTickAt"`
+	LastSpawnAt time.Time      `json:"lastSpawnAt"`
+	RNG         []byte         `json:"rng"`
+	Flights     []flight.State `json:"flights"`
+	// Weather is absent when storms are disabled.
+	Weather    []weatherCell `json:"weather,omitempty"`
+	WeatherRNG []byte        `json:"weatherRng,omitempty"`
+	// Tracks, detours and slots are keyed by flight ID, queues by airport.
+	Tracks  map[string][]trackPoint  `json:"tracks,omitempty"`
+	Detours map[string]*detour       `json:"detours,omitempty"`
+	Slots   map[string]*slot         `json:"slots,omitempty"`
+	Queues  map[string]*airportQueue `json:"queues,omitempty"`
+}
+
+func (s *Simulator) Snapshot() (Snapshot, error) {
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+
+	rng, err := s.flights.rngSource.MarshalBinary()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	weatherRNG, err := s.flights.weather.rngSource.MarshalBinary()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snap := Snapshot{
+		Version:     snapshotVersion,
+		SimTime:     s.clock.Now(),
+		TimeScale:   s.TimeScale(),
+		Seq:         s.seq,
+		Ticks:       s.ticks,
+		LastTickAt:  s.flights.lastTickAt,
+		LastSpawnAt: s.flights.lastSpawnAt,
+		RNG:         rng,
+		Weather:     s.flights.weather.list(),
+		WeatherRNG:  weatherRNG,
+	}
+
+	s.flights.mu.RLock()
+	defer s.flights.mu.RUnlock()
+	snap.Flights = make([]flight.State, 0, len(s.flights.flights))
+	for _, id := range sortedIDs(s.flights.flights) {
+		snap.Flights = append(snap.Flights, *s.flights.flights[id])
+	}
+	// Copied so the snapshot doesn't change under an encoder once unlocked
+	snap.Tracks = make(map[string][]trackPoint, len(s.flights.tracks))
+	for id, t := range s.flights.tracks {
+		snap.Tracks[id] = t.list()
+	}
+	snap.Detours = make(map[string]*detour, len(s.flights.detours))
+	for id, d := range s.flights.detours {
+		snap.Detours[id] = &detour{Cell: d.Cell, Waypoints: slices.Clone(d.Waypoints)}
+	}
+	snap.Slots = make(map[string]*slot, len(s.flights.slots))
+	for id, sl := range s.flights.slots {
+		c := *sl
+		if sl.Hold != nil {
+			h := *sl.Hold
+			c.Hold = &h
+		}
+		snap.Slots[id] = &c
+	}
+	snap.Queues = make(map[string]*airportQueue, len(s.flights.queues))
+	for code, q := range s.flights.queues {
+		c := *q
+		snap.Queues[code] = &c
+	}
+	return snap, nil
+}
+
+// Restore replaces the simulation state with snap. A controllable clock is
+// moved back to the snapshot's sim time so the first tick does not jump.
+// Weather cells are skipped when the simulator runs without weather.
+func (s *Simulator) Restore(snap Snapshot) error {
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+
+	if err := s.flights.rngSource.UnmarshalBinary(snap.RNG); err != nil {
+		return fmt.Errorf("restore rng: %w", err)
+	}
+	if len(snap.WeatherRNG) > 0 {
+		if err := s.flights.weather.rngSource.UnmarshalBinary(snap.WeatherRNG); err != nil {
+			return fmt.Errorf("restore weather rng: %w", err)
+		}
+	}
+	if c, ok := s.clock.(*SimClock); ok {
+		c.Set(snap.SimTime)
+		c.SetScale(snap.TimeScale)
+	}
+	s.seq, s.ticks = snap.Seq, snap.Ticks
+	s.lastMu.Lock()
+	s.last = nil // delta clients must start over from a full frame
+	s.lastMu.Unlock()
+
+	s.flights.mu.Lock()
+	defer s.flights.mu.Unlock()
+	s.flights.lastTickAt, s.flights.lastSpawnAt = snap.LastTickAt, snap.LastSpawnAt
+	s.flights.flights = make(map[string]*flight.State, len(snap.Flights))
+	s.flights.tracks = make(map[string]*track, len(snap.Tracks))
+	for id, points := range snap.Tracks {
+		s.flights.tracks[id] = restoreTrack(points)
+	}
+	s.flights.grid = newFlightGrid()
+	s.flights.detours = nonNil(snap.Detours)
+	s.flights.queues = nonNil(snap.Queues)
+	s.flights.slots = nonNil(snap.Slots)
+	s.flights.arrivals = &arrivalLog{}
+	// Flights would otherwise turn around storms that are never published
+	if s.flights.weather.target > 0 {
+		s.flights.weather.restore(snap.Weather)
+	}
+	for i := range snap.Flights {
+		f := snap.Flights[i]
+		s.flights.flights[f.ID] = &f
+		s.flights.grid.move(&f)
+	}
+	return nil
+}
+
+// SaveSnapshot writes atomically so a crash mid-write never leaves a
+// truncated file for the next start to restore.
+func (s *Simulator) SaveSnapshot(path string) error {
+	snap, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func LoadSnapshot(path string) (Snapshot, error) {
+	var snap Snapshot
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return snap, err
+	}
+	err = json.Unmarshal(raw, &snap)
+	return snap, err
+}
+
+func nonNil[V any](m map[string]V) map[string]V {
+	if m == nil {
+		return make(map[string]V)
+	}
+	return m
+}
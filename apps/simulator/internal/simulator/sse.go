@@ -0,0 +1,135 @@
+package simulator
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sseConn writes queued frames as Server-Sent Events. Binary frames never
+// reach it since the proto format is negotiated on WebSockets only.
+type sseConn struct {
+	w          http.ResponseWriter
+	rc         *http.ResponseController
+	remoteAddr string
+}
+
+func (c *sseConn) writeFrame(f outFrame) error {
+	c.rc.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	var b strings.Builder
+	if f.seq > 0 {
+		b.WriteString("id: ")
+		b.WriteString(strconv.FormatInt(f.seq, 10))
+		b.WriteByte('\n')
+	}
+	b.WriteString("data: ")
+	b.Write(f.data)
+	b.WriteString("\n\n")
+	if _, err := c.w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	return c.rc.Flush()
+}
+
+// Close is a no-op; the handler ends the response once the client is removed.
+func (c *sseConn) Close() error {
+	return nil
+}
+
+func (c *sseConn) RemoteAddr() string {
+	return c.remoteAddr
+}
+
+// serveFlightsSSE streams the same frames as /ws/flights through the shared
+// client store. Each event's id is the frame seq; a delta client reconnecting
+// with a Last-Event-ID equal to the latest seq carries on without a full
+// frame. There is no upstream channel, so the viewport comes from ?bbox=w,s,e,n
+// and optional &zoom=. ?events= works as on /ws/flights.
+func serveFlightsSSE(w http.ResponseWriter, r *http.Request, clients *clientStore, initial func(*client)) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	format, err := parseEncoding(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events, err := parseEvents(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	vp, err := viewportFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lastEventID, _ := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	c := clients.add(&sseConn{w: w, rc: rc, remoteAddr: r.RemoteAddr}, format)
+	c.mu.Lock()
+	c.viewport, c.events = vp, events
+	if format == formatDelta {
+		c.seq = lastEventID
+	}
+	c.mu.Unlock()
+	log.Printf("SSE client connected")
+
+	go initial(c)
+
+	select {
+	case <-r.Context().Done():
+	case <-c.done:
+	}
+	clients.remove(c)
+	// The writer must be finished with w before the handler returns
+	<-c.stopped
+	log.Printf("SSE client disconnected")
+}
+
+func viewportFromQuery(r *http.Request) (*viewport, error) {
+	q := r.URL.Query()
+	if q.Get("bbox") == "" {
+		return nil, nil
+	}
+	bbox, err := parseBBox(q.Get("bbox"))
+	if err != nil {
+		return nil, err
+	}
+	zoom := minViewportZoom
+	if z := q.Get("zoom"); z != "" {
+		v, err := strconv.ParseFloat(z, 64)
+		if err != nil {
+			return nil, errors.New("zoom must be a number")
+		}
+		zoom = v
+	}
+	return newViewport(bbox, zoom)
+}
+
+// parseBBox reads "west,south,east,north" from a query parameter.
+func parseBBox(v string) ([]float64, error) {
+	parts := strings.Split(v, ",")
+	bbox := make([]float64, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, errors.New("bbox must be four comma-separated numbers")
+		}
+		bbox[i] = f
+	}
+	return bbox, nil
+}
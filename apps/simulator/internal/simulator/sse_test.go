@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// firstSSEFrame reads the data of the first event on /sse/flights?query.
+func firstSSEFrame(t *testing.T, srv *httptest.Server, query string) flightsGeoJSONMessage {
+	t.Helper()
+	resp, err := http.Get(srv.URL + "/sse/flights?" + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %s", resp.Status)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var msg flightsGeoJSONMessage
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+	t.Fatalf("stream ended without an event: %v", scanner.Err())
+	return flightsGeoJSONMessage{}
+}
+
+func TestSSEInitialFrameViewport(t *testing.T) {
+	s, _ := newTestSimulator(t, 1)
+	if err := s.Step(60); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewRouter(s, s.airports))
+	defer srv.Close()
+
+	total := s.FlightCount()
+	if total == 0 {
+		t.Fatal("no flights to filter")
+	}
+	if msg := firstSSEFrame(t, srv, ""); len(msg.FeatureCollection.Features) != total {
+		t.Errorf("unfiltered first frame has %d of %d flights", len(msg.FeatureCollection.Features), total)
+	}
+	// Over the Indian Ocean, far from every test airport
+	if msg := firstSSEFrame(t, srv, "bbox=100,-10,110,0&zoom=6"); len(msg.FeatureCollection.Features) != 0 {
+		t.Errorf("first frame over an empty area has %d of %d flights", len(msg.FeatureCollection.Features), total)
+	}
+	msg := firstSSEFrame(t, srv, "bbox=-130,20,-60,55&zoom=6")
+	if len(msg.FeatureCollection.Features) == 0 {
+		t.Error("first frame over the US has no flights")
+	}
+	for _, f := range msg.FeatureCollection.Features {
+		coords := f.Geometry.Coordinates.([]any)
+		lon, lat := coords[0].(float64), coords[1].(float64)
+		if lon < -130 || lon > -60 || lat < 20 || lat > 55 {
+			t.Errorf("flight %v at %g,%g is outside the bbox", f.Properties["id"], lon, lat)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/data"
+)
+
+// ScheduledFlight is one timetable row. Times are UTC offsets from midnight;
+// without HasArrival the arrival is computed from distance and aircraft.
+type ScheduledFlight struct {
+	FlightNumber string
+	Airline      string
+	Origin       string
+	Destination  string
+	Departure    time.Duration
+	Arrival      time.Duration
+	HasArrival   bool
+	Days         [7]bool // Monday first
+	AircraftType string
+}
+
+type Timetable struct {
+	Flights []ScheduledFlight
+}
+
+type scheduledFlightJSON struct {
+	FlightNumber string `json:"flight"`
+	Airline      string `json:"airline"`
+	Origin       string `json:"origin"`
+	Destination  string `json:"destination"`
+	Departure    string `json:"std"`
+	Arrival      string `json:"sta"`
+	Days         string `json:"days"`
+	AircraftType string `json:"aircraft"`
+}
+
+var timetableColumns = []string{"flight", "airline", "origin", "destination", "std", "sta", "days", "aircraft"}
+
+// LoadTimetable reads a .json array or a .csv file with the header
+// flight,airline,origin,destination,std,sta,days,aircraft. Times are HH:MM
+// UTC and days are ISO weekday digits, e.g. "12345" or "1.3.5..".
+func LoadTimetable(path string) (*Timetable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rows []scheduledFlightJSON
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.NewDecoder(file).Decode(&rows)
+	} else {
+		rows, err = readTimetableCSV(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Timetable{Flights: make([]ScheduledFlight, 0, len(rows))}
+	for i, row := range rows {
+		sf, err := row.parse()
+		if err != nil {
+			return nil, fmt.Errorf("timetable entry %d (%s): %w", i+1, row.FlightNumber, err)
+		}
+		t.Flights = append(t.Flights, sf)
+	}
+	return t, nil
+}
+
+func readTimetableCSV(r io.Reader) ([]scheduledFlightJSON, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	index := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, col := range timetableColumns {
+		if _, ok := index[col]; !ok && col != "sta" {
+			return nil, fmt.Errorf("timetable: missing column %q", col)
+		}
+	}
+	field := func(rec []string, col string) string {
+		if i, ok := index[col]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	rows := make([]scheduledFlightJSON, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		rows = append(rows, scheduledFlightJSON{
+			FlightNumber: field(rec, "flight"), Airline: field(rec, "airline"),
+			Origin: field(rec, "origin"), Destination: field(rec, "destination"),
+			Departure: field(rec, "std"), Arrival: field(rec, "sta"),
+			Days: field(rec, "days"), AircraftType: field(rec, "aircraft"),
+		})
+	}
+	return rows, nil
+}
+
+func (row scheduledFlightJSON) parse() (ScheduledFlight, error) {
+	sf := ScheduledFlight{
+		FlightNumber: row.FlightNumber, Airline: row.Airline,
+		Origin: row.Origin, Destination: row.Destination, AircraftType: row.AircraftType,
+	}
+	if sf.FlightNumber == "" || sf.Origin == "" || sf.Destination == "" {
+		return sf, fmt.Errorf("flight, origin and destination are required")
+	}
+	if sf.Origin == sf.Destination {
+		return sf, fmt.Errorf("origin and destination are the same")
+	}
+	if _, ok := data.LookupAircraftType(sf.AircraftType); !ok {
+		return sf, fmt.Errorf("unknown aircraft type %q", sf.AircraftType)
+	}
+	var err error
+	if sf.Departure, err = parseClock(row.Departure); err != nil {
+		return sf, fmt.Errorf("std: %w", err)
+	}
+	if row.Arrival != "" {
+		if sf.Arrival, err = parseClock(row.Arrival); err != nil {
+			return sf, fmt.Errorf("sta: %w", err)
+		}
+		sf.HasArrival = true
+	}
+	days := row.Days
+	if days == "" {
+		days = "1234567"
+	}
+	for _, c := range days {
+		switch {
+		case c >= '1' && c <= '7':
+			sf.Days[c-'1'] = true
+		case c == '.' || c == '-':
+		default:
+			return sf, fmt.Errorf("invalid day %q", c)
+		}
+	}
+	return sf, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (sf ScheduledFlight) operatesOn(day time.Time) bool {
+	return sf.Days[(int(day.Weekday())+6)%7]
+}
+
+// due returns the departures that fall in (from, to] in UTC.
+func (t *Timetable) due(from, to time.Time) []scheduledDeparture {
+	from, to = from.UTC(), to.UTC()
+	if to.Sub(from) > 24*time.Hour {
+		from = to.Add(-24 * time.Hour)
+	}
+	var result []scheduledDeparture
+	for day := from.Truncate(24 * time.Hour); !day.After(to); day = day.Add(24 * time.Hour) {
+		for _, sf := range t.Flights {
+			if !sf.operatesOn(day) {
+				continue
+			}
+			if std := day.Add(sf.Departure); std.After(from) && !std.After(to) {
+				result = append(result, scheduledDeparture{ScheduledFlight: sf, std: std})
+			}
+		}
+	}
+	return result
+}
+
+type scheduledDeparture struct {
+	ScheduledFlight
+	std time.Time
+}
+
+// realSTA resolves the timetable's arrival, rolling past midnight when
+// needed.
+func (d scheduledDeparture) realSTA() (time.Time, bool) {
+	if !d.HasArrival {
+		return time.Time{}, false
+	}
+	sta := d.std.Truncate(24 * time.Hour).Add(d.Arrival)
+	for !sta.After(d.std) {
+		sta = sta.Add(24 * time.Hour)
+	}
+	return sta, true
+}
+
+// sta is the scheduled arrival in sim time for a flight of distance nm at
+// cruise. Timetable times are real-world, so the scheduled block time is
+// carried over as a share of the real-world estimate: a timetable that pads
+// its block by 10% gets a sim STA 10% after the simulated block time.
+func (d scheduledDeparture) sta(distance, cruise float64) (time.Time, bool) {
+	sta, ok := d.realSTA()
+	if !ok {
+		return time.Time{}, false
+	}
+	ac, _ := data.LookupAircraftType(d.AircraftType)
+	share := sta.Sub(d.std).Hours() / realBlockTime(distance, ac).Hours()
+	return d.std.Add(time.Duration(float64(blockTime(distance, cruise, ac)) * share)), true
+}
+
+func (s *flightStore) scheduledSpawn(now time.Time, airports *AirportStore) {
+	for _, d := range s.timetable.due(s.lastSpawnAt, now) {
+		if _, ok := airports.Airports[d.Origin]; !ok {
+			continue
+		}
+		if _, ok := airports.Airports[d.Destination]; !ok {
+			continue
+		}
+		// A repeated entry must not take a slot from the flight already flying
+		if _, exists := s.get(flightID(d.FlightNumber, d.Origin, d.Destination)); exists {
+			continue
+		}
+		ac, _ := data.LookupAircraftType(d.AircraftType)
+		f := s.createFlight(d.Origin, d.Destination, airlineName(d.Airline), d.FlightNumber, ac, airports, d.std)
+		if f == nil {
+			continue
+		}
+		if sta, ok := d.sta(routeLength(f.Waypoints), f.CruiseAltitude); ok {
+			f.ScheduledArrival = sta.Format(time.RFC3339)
+		}
+		s.add(f)
+	}
+	s.lastSpawnAt = now
+}
+
+func airlineName(icao string) string {
+	for _, a := range data.Airlines {
+		if a.ICAOCode == icao {
+			return a.Name
+		}
+	}
+	return icao
+}
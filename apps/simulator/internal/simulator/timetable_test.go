@@ -0,0 +1,153 @@
+package simulator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+)
+
+// testTimetable loads a timetable from CSV rows, without the header.
+func testTimetable(t *testing.T, rows ...string) *Timetable {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schedule.csv")
+	raw := "flight,airline,origin,destination,std,sta,days,aircraft\n" + strings.Join(rows, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tt, err := LoadTimetable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tt
+}
+
+func TestTimetableMidnightArrival(t *testing.T) {
+	tt := testTimetable(t,
+		"AAL100,AAL,JFK,LHR,17:00,00:00,1234567,B77W",
+		"BAW304,BAW,LHR,CDG,07:30,,1234567,A320")
+
+	due := tt.due(testStart, testStart.Add(24*time.Hour))
+	if len(due) != 2 {
+		t.Fatalf("got %d departures, want 2", len(due))
+	}
+	for _, d := range due {
+		sta, ok := d.realSTA()
+		switch d.FlightNumber {
+		case "AAL100":
+			if want := testStart.Add(24 * time.Hour); !ok || !sta.Equal(want) {
+				t.Errorf("AAL100 sta = %v, %v; want %v", sta, ok, want)
+			}
+		case "BAW304":
+			if ok {
+				t.Errorf("BAW304 has sta %v without one in the timetable", sta)
+			}
+		}
+	}
+}
+
+func TestTimetableRepeatedEntry(t *testing.T) {
+	tt := testTimetable(t,
+		"BAW1,BAW,LHR,JFK,00:01,,1234567,B77W",
+		"BAW1,BAW,LHR,JFK,00:01,,1234567,B77W")
+	s, _ := newTestSimulator(t, 1, WithTimetable(tt))
+	if err := s.Step(6 * 61); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.FlightCount(); n != 1 {
+		t.Fatalf("got %d flights, want 1", n)
+	}
+	if len(s.flights.slots) != 0 {
+		t.Errorf("the flight was given a departure slot behind its own repeat")
+	}
+	std := testStart.Add(time.Minute)
+	gap := spacing(DefaultCapacityConfig.Departures, len(activeRunways(s.airports.Airports["LHR"], s.flights.wind)))
+	if next := s.flights.queues["LHR"].NextDeparture; !next.Equal(std.Add(gap)) {
+		t.Errorf("next departure at %v, want one slot after %v", next, std)
+	}
+}
+
+func TestTimetabledFlightLandsOnSchedule(t *testing.T) {
+	tt := testTimetable(t,
+		"BAW117,BAW,LHR,JFK,00:01,,1234567,B77W",
+		"AAL100,AAL,JFK,LHR,00:01,07:01,1234567,B77W",
+		"UAL123,UAL,ORD,SFO,00:01,,1234567,B738",
+		"AAL3055,AAL,BOS,ATL,00:01,,1234567,A321")
+	s, _ := newTestSimulator(t, 1, WithTimetable(tt), WithWeather(0), WithCapacity(CapacityConfig{}))
+	if err := s.Step(6 * 61); err != nil {
+		t.Fatal(err)
+	}
+	flights := s.flights.snapshot()
+	if len(flights) != 4 {
+		t.Fatalf("got %d flights, want 4", len(flights))
+	}
+	landed := make(map[string]time.Time)
+	etas := make(map[string][]time.Time)
+	for i := 0; i < 6*3600 && len(landed) < len(flights); i++ {
+		if err := s.Step(1); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range flights {
+			if _, ok := landed[f.ID]; ok {
+				continue
+			}
+			g, ok := s.flights.lookup(f.ID)
+			if !ok || g.Phase == flight.Landed {
+				landed[f.ID] = s.SimTime()
+				continue
+			}
+			etas[f.ID] = append(etas[f.ID], parseTime(g.EstimatedArrival))
+		}
+	}
+	for _, f := range flights {
+		std, sta := parseTime(f.ScheduledDeparture), parseTime(f.ScheduledArrival)
+		at, ok := landed[f.ID]
+		if !ok {
+			t.Errorf("%s has not landed", f.ID)
+			continue
+		}
+		// AAL100's timetable pads its block a little, so it lands early
+		if off := at.Sub(sta); off.Abs() > sta.Sub(std)/10 {
+			t.Errorf("%s landed %v from its STA %v after a %v block", f.ID, off, sta, sta.Sub(std))
+		}
+		for _, eta := range etas[f.ID] {
+			if off := at.Sub(eta); off.Abs() > 5*time.Second {
+				t.Errorf("%s landed %v from its ETA %v", f.ID, off, eta)
+				break
+			}
+		}
+	}
+}
+
+func TestHeldFlightRunsLate(t *testing.T) {
+	// Four flights at once into an airport that lands one an hour per runway
+	tt := testTimetable(t,
+		"AAL1,AAL,BOS,JFK,00:01,,1234567,A321",
+		"AAL2,AAL,BOS,JFK,00:01,,1234567,A321",
+		"AAL3,AAL,BOS,JFK,00:01,,1234567,A321",
+		"AAL4,AAL,BOS,JFK,00:01,,1234567,A321")
+	s, _ := newTestSimulator(t, 1, WithTimetable(tt), WithWeather(0), WithCapacity(CapacityConfig{Arrivals: 1}))
+	if err := s.Step(6 * 61); err != nil {
+		t.Fatal(err)
+	}
+	gap := spacing(1, len(activeRunways(s.airports.Airports["JFK"], s.flights.wind)))
+	for i := 0; i < 6*3600 && s.FlightCount() > 0; i++ {
+		if err := s.Step(1); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range s.flights.snapshot() {
+			if f.Phase != flight.Holding {
+				continue
+			}
+			sta, eta := parseTime(f.ScheduledArrival), parseTime(f.EstimatedArrival)
+			if eta.Sub(sta) < gap/2 {
+				t.Fatalf("%s is holding with ETA %v, only %v past its STA %v", f.ID, eta, eta.Sub(sta), sta)
+			}
+			return
+		}
+	}
+	t.Fatal("no flight held for a landing slot")
+}
@@ -0,0 +1,66 @@
+package simulator
+
+import (
+	"time"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+)
+
+const (
+	// trackPoints at one per trackInterval of sim time covers a typical
+	// (time-compressed) flight from takeoff.
+	trackPoints   = 256
+	trackInterval = 2 * time.Second
+)
+
+// trackPoint carries ground speed rather than airspeed, so the speeds along
+// a trail match how far apart its points are in the wind.
+type trackPoint struct {
+	Longitude   float64   `json:"longitude"`
+	Latitude    float64   `json:"latitude"`
+	Altitude    float64   `json:"altitude"`
+	GroundSpeed float64   `json:"groundSpeed"`
+	Time        time.Time `json:"time"`
+}
+
+// track is a ring buffer of a flight's past positions, oldest overwritten
+// first.
+type track struct {
+	points []trackPoint
+	next   int
+	last   time.Time
+}
+
+// record samples f unless the last sample is less than trackInterval old.
+func (t *track) record(f *flight.State, now time.Time) {
+	if len(t.points) > 0 && now.Sub(t.last) < trackInterval {
+		return
+	}
+	t.last = now
+	p := trackPoint{
+		Longitude: f.Position.Longitude, Latitude: f.Position.Latitude, Altitude: f.Position.Altitude,
+		GroundSpeed: f.GroundSpeed, Time: now,
+	}
+	if len(t.points) < trackPoints {
+		t.points = append(t.points, p)
+		return
+	}
+	t.points[t.next] = p
+	t.next = (t.next + 1) % trackPoints
+}
+
+// restoreTrack rebuilds a track from points listed oldest first.
+func restoreTrack(points []trackPoint) *track {
+	t := &track{points: points}
+	if n := len(points); n > 0 {
+		t.last = points[n-1].Time
+	}
+	return t
+}
+
+// list copies the points oldest first.
+func (t *track) list() []trackPoint {
+	out := make([]trackPoint, 0, len(t.points))
+	out = append(out, t.points[t.next:]...)
+	return append(out, t.points[:t.next]...)
+}
@@ -0,0 +1,112 @@
+package simulator
+
+import (
+	"errors"
+	"math"
+
+	"github.com/hannan/voyager/simulator/internal/geo"
+)
+
+const (
+	// Below this zoom the globe shows a whole hemisphere and map bounds are
+	// unreliable, so subscribers get every flight.
+	minViewportZoom = 3.0
+	viewportPadding = 0.1
+)
+
+// viewport is a client's map bounds in degrees. West > East means the box
+// crosses the antimeridian.
+type viewport struct {
+	West, South, East, North float64
+	Zoom                     float64
+}
+
+// newViewport validates a [west, south, east, north] bbox as sent by map
+// libraries (longitudes may run past ±180) and pads it so aircraft appear
+// just before they enter the screen.
+func newViewport(bbox []float64, zoom float64) (*viewport, error) {
+	return makeViewport(bbox, zoom, viewportPadding)
+}
+
+// boundingBox is an unpadded viewport for API queries.
+func boundingBox(bbox []float64) (*viewport, error) {
+	return makeViewport(bbox, minViewportZoom, 0)
+}
+
+func makeViewport(bbox []float64, zoom, padding float64) (*viewport, error) {
+	if len(bbox) != 4 {
+		return nil, errors.New("bbox must be [west, south, east, north]")
+	}
+	west, south, east, north := bbox[0], bbox[1], bbox[2], bbox[3]
+	for _, v := range bbox {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, errors.New("bbox must be finite")
+		}
+	}
+	if south > north || south < -90 || north > 90 {
+		return nil, errors.New("bbox latitudes must satisfy -90 <= south <= north <= 90")
+	}
+	if east < west {
+		east += 360
+	}
+
+	padLon, padLat := (east-west)*padding, (north-south)*padding
+	v := &viewport{
+		West: west - padLon, East: east + padLon,
+		South: math.Max(-90, south-padLat), North: math.Min(90, north+padLat),
+		Zoom: zoom,
+	}
+	if v.East-v.West >= 360 {
+		v.West, v.East = -180, 180
+	} else {
+		v.West, v.East = normalizeLongitude(v.West), normalizeLongitude(v.East)
+	}
+	return v, nil
+}
+
+// viewportKey maps every unfiltered subscription to the zero viewport so
+// they share one encoded frame.
+func viewportKey(v *viewport) viewport {
+	if v == nil || v.Zoom < minViewportZoom {
+		return viewport{}
+	}
+	return *v
+}
+
+func (v viewport) contains(lon, lat float64) bool {
+	if v.Zoom < minViewportZoom {
+		return true
+	}
+	if lat < v.South || lat > v.North {
+		return false
+	}
+	if lon < -180 || lon > 180 {
+		// Great-circle steps can carry a flight past the antimeridian
+		lon = normalizeLongitude(lon)
+	}
+	if v.West <= v.East {
+		return lon >= v.West && lon <= v.East
+	}
+	return lon >= v.West || lon <= v.East
+}
+
+func (v viewport) filter(fc geo.FeatureCollection) geo.FeatureCollection {
+	if v.Zoom < minViewportZoom {
+		return fc
+	}
+	features := make([]geo.Feature, 0, len(fc.Features)/4)
+	for _, f := range fc.Features {
+		if coords, ok := f.Geometry.Coordinates.([]float64); ok && len(coords) >= 2 && v.contains(coords[0], coords[1]) {
+			features = append(features, f)
+		}
+	}
+	return geo.NewFeatureCollection(features)
+}
+
+func normalizeLongitude(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
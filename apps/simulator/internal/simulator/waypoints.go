@@ -0,0 +1,99 @@
+package simulator
+
+import (
+	"math"
+
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+	"github.com/hannan/voyager/simulator/internal/nav"
+)
+
+// planWaypoints is the airway route between two airports, or the great
+// circle when there is no network or no route on it is short enough.
+func planWaypoints(network *nav.Network, dep, arr string, positions map[string]flight.Position) []flight.Waypoint {
+	from, to := airportWaypoint(dep, positions[dep]), airportWaypoint(arr, positions[arr])
+	if network != nil {
+		if route, ok := network.Route(from, to); ok {
+			return route
+		}
+	}
+	return []flight.Waypoint{from, to}
+}
+
+func airportWaypoint(code string, pos flight.Position) flight.Waypoint {
+	return flight.Waypoint{Name: code, Latitude: pos.Latitude, Longitude: pos.Longitude}
+}
+
+func setRoute(f *flight.State, waypoints []flight.Waypoint) {
+	f.Route, f.Waypoints, f.NextWaypoint = nav.RouteString(waypoints), waypoints, 1
+	sequenceRoute(f, 0)
+}
+
+// ensureRoute gives a flight restored from a snapshot taken before routes
+// existed the great circle it was flying.
+func ensureRoute(f *flight.State, positions map[string]flight.Position) {
+	if len(f.Waypoints) >= 2 && f.NextWaypoint > 0 && f.NextWaypoint < len(f.Waypoints) {
+		return
+	}
+	setRoute(f, []flight.Waypoint{
+		airportWaypoint(f.DepartureAirport, positions[f.DepartureAirport]),
+		airportWaypoint(f.ArrivalAirport, positions[f.ArrivalAirport]),
+	})
+}
+
+// sequenceRoute moves on to the next leg once f is within reach nm of the
+// fix it is flying to, so a flight covering more than waypointReached in a
+// step turns as it passes the fix rather than doubling back.
+func sequenceRoute(f *flight.State, reach float64) {
+	reach = math.Max(reach, waypointReached)
+	for {
+		f.FixDistance = geo.CalculateDistance(f.Position, f.Waypoints[f.NextWaypoint].Position())
+		if f.NextWaypoint == len(f.Waypoints)-1 || f.FixDistance > reach {
+			break
+		}
+		f.NextWaypoint++
+	}
+	f.NextFix = f.Waypoints[f.NextWaypoint].Name
+	f.FixBearing = geo.CalculateBearing(f.Position, f.Waypoints[f.NextWaypoint].Position())
+}
+
+func routeLength(waypoints []flight.Waypoint) float64 {
+	total := 0.0
+	for i := 1; i < len(waypoints); i++ {
+		total += geo.CalculateDistance(waypoints[i-1].Position(), waypoints[i].Position())
+	}
+	return total
+}
+
+// routePosition is the point distance nm along the route from its start.
+func routePosition(waypoints []flight.Waypoint, distance float64) flight.Position {
+	for i := 1; i < len(waypoints); i++ {
+		from, to := waypoints[i-1].Position(), waypoints[i].Position()
+		leg := geo.CalculateDistance(from, to)
+		if distance <= leg || i == len(waypoints)-1 {
+			return geo.InterpolatePosition(from, to, math.Min(1, safeRatio(distance, leg)))
+		}
+		distance -= leg
+	}
+	return waypoints[0].Position()
+}
+
+// routeCoordinates spreads about n segments over the legs by length,
+// keeping every waypoint as a vertex.
+func routeCoordinates(waypoints []flight.Waypoint, n int) [][]float64 {
+	total := routeLength(waypoints)
+	coords := [][]float64{{waypoints[0].Longitude, waypoints[0].Latitude}}
+	for i := 1; i < len(waypoints); i++ {
+		from, to := waypoints[i-1].Position(), waypoints[i].Position()
+		segments := max(1, int(math.Round(float64(n)*safeRatio(geo.CalculateDistance(from, to), total))))
+		coords = append(coords, geo.GenerateGreatCircleCoordinates(from, to, segments)[1:]...)
+	}
+	return coords
+}
+
+func safeRatio(a, b float64) float64 {
+	if b <= 0 {
+		return 0
+	}
+	return a / b
+}
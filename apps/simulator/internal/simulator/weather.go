@@ -0,0 +1,393 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	mathrand "math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hannan/voyager/simulator/internal/data"
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"github.com/hannan/voyager/simulator/internal/geo"
+	"github.com/hannan/voyager/simulator/internal/wind"
+)
+
+const (
+	// DefaultWeatherCells is how many storms are kept alive at once.
+	DefaultWeatherCells = 40
+
+	cellVertices = 16
+	// Lifetimes are sim time; at the usual compression a cell lasts one to
+	// three hours of flying.
+	minCellLifetime = 60 * time.Second
+	maxCellLifetime = 180 * time.Second
+	minCellRadius   = 20.0 // nm
+	maxCellRadius   = 70.0
+	// Cells spawn this far, in nm, from a random airport so they sit where
+	// the traffic is.
+	maxCellOffset = 400.0
+	// Cells drift with the wind at this altitude plus a little of their own.
+	steeringAltitude = 18000.0
+	maxCellSpin      = 15.0 // kt
+
+	// avoidIntensity is the weakest cell flights go around; lighter rain
+	// is flown through.
+	avoidIntensity = 0.4
+	// avoidLookAhead is how far down the route, in nm, flights look for cells.
+	avoidLookAhead = 300.0
+	// avoidMargin is the clearance, in nm, kept from a cell's outer edge.
+	avoidMargin = 15.0
+	// rejoinFactor places the point back on the route this many clearances
+	// past the cell, so the return leg does not clip it.
+	rejoinFactor = 2.5
+	// waypointReached is how close, in nm, counts as passing a waypoint.
+	waypointReached = 1.0
+)
+
+// weatherCell is a convective storm: an irregular polygon around a drifting
+// center whose intensity builds to Peak halfway through its life and dies
+// away. Flights below Top steer around it.
+type weatherCell struct {
+	ID     string          `json:"id"`
+	Center flight.Position `json:"center"`
+	// Radii are nm from the center, for vertices at evenly spaced bearings
+	// clockwise from north.
+	Radii   []float64       `json:"radii"`
+	Top     float64         `json:"top"`
+	Peak    float64         `json:"peak"`
+	Drift   flight.Velocity `json:"drift"`
+	Born    time.Time       `json:"born"`
+	Expires time.Time       `json:"expires"`
+}
+
+func (c *weatherCell) intensity(now time.Time) float64 {
+	life, age := c.Expires.Sub(c.Born), now.Sub(c.Born)
+	if life <= 0 || age < 0 || age >= life {
+		return 0
+	}
+	return c.Peak * math.Sin(math.Pi*float64(age)/float64(life))
+}
+
+func (c *weatherCell) radius() float64 {
+	r := 0.0
+	for _, v := range c.Radii {
+		r = math.Max(r, v)
+	}
+	return r
+}
+
+func (c *weatherCell) polygon() [][][]float64 {
+	ring := make([][]float64, 0, len(c.Radii)+1)
+	for i, r := range c.Radii {
+		p := geo.Destination(c.Center, float64(i)*360/float64(len(c.Radii)), r)
+		ring = append(ring, []float64{p.Longitude, p.Latitude})
+	}
+	ring = append(ring, ring[0])
+	return [][][]float64{ring}
+}
+
+func (c *weatherCell) feature(now time.Time) geo.Feature {
+	return geo.NewPolygonFeature(c.polygon(), map[string]interface{}{
+		"id": c.ID, "intensity": c.intensity(now), "top": c.Top, "radiusNm": c.radius(),
+		"expires": c.Expires.Format(time.RFC3339),
+	})
+}
+
+// visible reports whether any part of the cell's outline is inside vp.
+func (c *weatherCell) visible(vp viewport) bool {
+	if vp.contains(c.Center.Longitude, c.Center.Latitude) {
+		return true
+	}
+	for _, p := range c.polygon()[0] {
+		if vp.contains(p[0], p[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// weatherStore keeps the live cells topped up. It is stepped from
+// flightStore.update and read by handlers, so it has its own lock.
+type weatherStore struct {
+	mu        sync.RWMutex
+	cells     []*weatherCell
+	target    int
+	rng       *mathrand.Rand
+	rngSource *mathrand.PCG
+}
+
+func newWeatherStore(target int, seed uint64) *weatherStore {
+	src := newRandSource(seed + 1)
+	return &weatherStore{target: target, rng: mathrand.New(src), rngSource: src}
+}
+
+// update drifts the cells, drops the expired ones and spawns replacements.
+// The first call fills the sky with cells of random age so they don't all
+// expire together.
+func (w *weatherStore) update(now time.Time, dt float64, airports *AirportStore, field wind.Field) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	live := w.cells[:0]
+	for _, c := range w.cells {
+		if now.Before(c.Expires) {
+			speed := math.Hypot(c.Drift.X, c.Drift.Y)
+			bearing := math.Atan2(c.Drift.X, c.Drift.Y) * 180 / math.Pi
+			c.Center = geo.Destination(c.Center, bearing, speed*dt/3600)
+			live = append(live, c)
+		}
+	}
+	aged := len(w.cells) == 0
+	w.cells = live
+	if len(airports.Codes) == 0 {
+		return
+	}
+	for len(w.cells) < w.target {
+		w.cells = append(w.cells, w.spawn(now, aged, airports, field))
+	}
+}
+
+func (w *weatherStore) spawn(now time.Time, aged bool, airports *AirportStore, field wind.Field) *weatherCell {
+	rng := w.rng
+	origin := airports.Positions[airports.Codes[rng.IntN(len(airports.Codes))]]
+	center := geo.Destination(origin, rng.Float64()*360, rng.Float64()*maxCellOffset)
+	center.Altitude = 0
+
+	base := minCellRadius + rng.Float64()*(maxCellRadius-minCellRadius)
+	radii := make([]float64, cellVertices)
+	for i := range radii {
+		radii[i] = base * (0.6 + 0.4*rng.Float64())
+	}
+	u, v := field.At(center.Longitude, center.Latitude, steeringAltitude)
+	spin, spinDir := rng.Float64()*maxCellSpin, rng.Float64()*2*math.Pi
+	u, v = u+spin*math.Sin(spinDir), v+spin*math.Cos(spinDir)
+
+	life := minCellLifetime + time.Duration(rng.Float64()*float64(maxCellLifetime-minCellLifetime))
+	born := now
+	if aged {
+		born = now.Add(-time.Duration(rng.Float64() * float64(life)))
+	}
+	return &weatherCell{
+		ID: fmt.Sprintf("WX%08x", rng.Uint32()), Center: center, Radii: radii,
+		Top: 25000 + 20000*rng.Float64(), Peak: 0.3 + 0.7*rng.Float64(),
+		Drift: flight.Velocity{X: u * data.SpeedCompression, Y: v * data.SpeedCompression},
+		Born:  born, Expires: born.Add(life),
+	}
+}
+
+func (w *weatherStore) restore(cells []weatherCell) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cells = make([]*weatherCell, len(cells))
+	for i := range cells {
+		w.cells[i] = &cells[i]
+	}
+}
+
+// list returns copies of the live cells.
+func (w *weatherStore) list() []weatherCell {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	result := make([]weatherCell, len(w.cells))
+	for i, c := range w.cells {
+		result[i] = *c
+	}
+	return result
+}
+
+// hazard is a cell strong enough to go around, as flights see it.
+type hazard struct {
+	id     string
+	center flight.Position
+	top    float64
+	// clear is the distance in nm to keep from the center.
+	clear float64
+}
+
+// hazards returns the cells strong enough to go around, in ID order.
+func (w *weatherStore) hazards(now time.Time) []hazard {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var result []hazard
+	for _, c := range w.cells {
+		if c.intensity(now) >= avoidIntensity {
+			result = append(result, hazard{id: c.ID, center: c.Center, top: c.Top, clear: c.radius() + avoidMargin})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].id < result[j].id })
+	return result
+}
+
+func (w *weatherStore) featureCollection(now time.Time, vp viewport) geo.FeatureCollection {
+	cells := w.list()
+	features := make([]geo.Feature, 0, len(cells))
+	for i := range cells {
+		if cells[i].visible(vp) {
+			features = append(features, cells[i].feature(now))
+		}
+	}
+	return geo.NewFeatureCollection(features)
+}
+
+// detour is the way around one cell: abeam it on the far side from the
+// cell's center, then back onto the great circle past it.
+type detour struct {
+	Cell      string            `json:"cell"`
+	Waypoints []flight.Position `json:"waypoints"`
+}
+
+// routeTarget is where f should head this step: the next detour waypoint,
+// or dest. Flights in the climb, cruise or descent check the route ahead
+// for cells and plan a detour around the nearest one.
+func (s *flightStore) routeTarget(f *flight.State, dest flight.Position, hazards []hazard) flight.Position {
+	if d, ok := s.detours[f.ID]; ok {
+		if slices.ContainsFunc(hazards, func(h hazard) bool { return h.id == d.Cell }) {
+			for len(d.Waypoints) > 0 && geo.CalculateDistance(f.Position, d.Waypoints[0]) < waypointReached {
+				d.Waypoints = d.Waypoints[1:]
+			}
+			if len(d.Waypoints) > 0 {
+				return d.Waypoints[0]
+			}
+		}
+		delete(s.detours, f.ID)
+	}
+	if f.Phase != flight.Climb && f.Phase != flight.Cruise && f.Phase != flight.Descent {
+		return dest
+	}
+	if d := planDetour(f.Position, dest, hazards); d != nil {
+		s.detours[f.ID] = d
+		return d.Waypoints[0]
+	}
+	return dest
+}
+
+func planDetour(pos, dest flight.Position, hazards []hazard) *detour {
+	remaining := geo.CalculateDistance(pos, dest)
+	nearest := -1
+	var cross, along float64
+	for i, h := range hazards {
+		if pos.Altitude >= h.top {
+			continue
+		}
+		// Cheap flat-earth reject before the trigonometry
+		dx := normalizeLongitude(h.center.Longitude-pos.Longitude) * 60 * math.Cos(pos.Latitude*math.Pi/180)
+		dy := (h.center.Latitude - pos.Latitude) * 60
+		if math.Abs(dy) > avoidLookAhead+h.clear || math.Hypot(dx, dy) > 1.1*(avoidLookAhead+h.clear) {
+			continue
+		}
+		if geo.CalculateDistance(dest, h.center) < h.clear {
+			continue // nowhere to go but in
+		}
+		xt, at := geo.CrossTrack(pos, dest, h.center)
+		if math.Abs(xt) >= h.clear || at < h.clear || at-h.clear > math.Min(avoidLookAhead, remaining) {
+			continue
+		}
+		if nearest < 0 || at < along {
+			nearest, cross, along = i, xt, at
+		}
+	}
+	if nearest < 0 {
+		return nil
+	}
+
+	h := hazards[nearest]
+	foot := geo.Destination(pos, geo.CalculateBearing(pos, dest), along)
+	side := -90.0 // the cell is right of the route, so pass left of it
+	if cross < 0 {
+		side = 90
+	}
+	abeam := geo.Destination(foot, geo.CalculateBearing(foot, dest)+side, h.clear-math.Abs(cross))
+	abeam.Altitude = pos.Altitude
+	d := &detour{Cell: h.id, Waypoints: []flight.Position{abeam}}
+	if rejoin := along + h.clear*rejoinFactor; rejoin < remaining {
+		d.Waypoints = append(d.Waypoints, geo.Destination(pos, geo.CalculateBearing(pos, dest), rejoin))
+	}
+	return d
+}
+
+type weatherGeoJSONMessage struct {
+	Type              string                `json:"type"`
+	FeatureCollection geo.FeatureCollection `json:"featureCollection"`
+	SimTime           int64                 `json:"simTime"`
+}
+
+// publishWeather sends the cells to clients subscribed to weather, each
+// limited to its viewport.
+func (s *Simulator) publishWeather() {
+	if s.flights.weather.target == 0 {
+		return
+	}
+	now := s.clock.Now()
+	encoded := make(map[viewport][]byte)
+	s.clients.sendEvent(eventWeather, func(vp viewport) []byte {
+		if data, ok := encoded[vp]; ok {
+			return data
+		}
+		data, err := json.Marshal(weatherGeoJSONMessage{
+			Type: "weather_geojson", FeatureCollection: s.flights.weather.featureCollection(now, vp), SimTime: now.UnixMilli(),
+		})
+		if err != nil {
+			return nil
+		}
+		encoded[vp] = data
+		return data
+	})
+}
+
+// sendInitialWeather gives a newly connected weather subscriber the cells
+// without waiting for the next publish.
+func (s *Simulator) sendInitialWeather(c *client) {
+	c.mu.Lock()
+	subscribed, vp := c.events&eventWeather != 0, viewportKey(c.viewport)
+	c.mu.Unlock()
+	if !subscribed || s.flights.weather.target == 0 {
+		return
+	}
+	now := s.clock.Now()
+	data, err := json.Marshal(weatherGeoJSONMessage{
+		Type: "weather_geojson", FeatureCollection: s.flights.weather.featureCollection(now, vp), SimTime: now.UnixMilli(),
+	})
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	ok := c.enqueue(outFrame{messageType: websocket.TextMessage, data: data})
+	c.mu.Unlock()
+	if !ok {
+		s.clients.remove(c)
+	}
+}
+
+// weatherHandler serves the live cells as Polygon features with their
+// intensity (0 to 1), top (ft), radiusNm and expiry. ?bbox=w,s,e,n limits
+// it to cells reaching into the box.
+func weatherHandler(s *Simulator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		vp := viewport{}
+		if bbox := r.URL.Query().Get("bbox"); bbox != "" {
+			values, err := parseBBox(bbox)
+			if err == nil {
+				var b *viewport
+				if b, err = boundingBox(values); err == nil {
+					vp = *b
+				}
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(s.flights.weather.featureCollection(s.SimTime(), vp))
+	}
+}
@@ -0,0 +1,90 @@
+package simulator
+
+import (
+	"encoding/hex"
+	"math"
+	"time"
+
+	"github.com/hannan/voyager/simulator/api/flightsv1"
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"google.golang.org/protobuf/proto"
+)
+
+// protoSubprotocol selects FlightsFrame messages from api/flights.proto.
+const protoSubprotocol = "voyager.flights.v1+proto"
+
+var protoPhases = map[flight.Phase]flightsv1.Phase{
+	flight.Takeoff: flightsv1.Phase_PHASE_TAKEOFF,
+	flight.Climb:   flightsv1.Phase_PHASE_CLIMB,
+	flight.Cruise:  flightsv1.Phase_PHASE_CRUISE,
+	flight.Descent: flightsv1.Phase_PHASE_DESCENT,
+	flight.Landing: flightsv1.Phase_PHASE_LANDING,
+	flight.Landed:  flightsv1.Phase_PHASE_LANDED,
+	flight.Holding: flightsv1.Phase_PHASE_HOLDING,
+}
+
+// encodeFlightsFrame marshals a FlightsFrame for the flights inside vp, in
+// the order given.
+func encodeFlightsFrame(seq, serverTimestamp, simTime int64, timeScale float64, flights []*flight.State, vp viewport) []byte {
+	frame := &flightsv1.FlightsFrame{
+		Seq:               seq,
+		ServerTimestampMs: serverTimestamp,
+		SimTimeMs:         simTime,
+		TimeScale:         timeScale,
+		Flights:           make([]*flightsv1.Flight, 0, len(flights)),
+	}
+	for _, f := range flights {
+		if vp.contains(f.Position.Longitude, f.Position.Latitude) {
+			frame.Flights = append(frame.Flights, toProtoFlight(f))
+		}
+	}
+	data, err := proto.Marshal(frame)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func toProtoFlight(f *flight.State) *flightsv1.Flight {
+	trace, _ := hex.DecodeString(f.TraceID)
+	return &flightsv1.Flight{
+		Id:                   f.ID,
+		CallSign:             f.CallSign,
+		Airline:              f.Airline,
+		AircraftType:         f.AircraftType,
+		AircraftCategory:     aircraftType(f).Category,
+		DepartureAirport:     f.DepartureAirport,
+		ArrivalAirport:       f.ArrivalAirport,
+		Phase:                protoPhases[f.Phase],
+		LongitudeE5:          int32(math.Round(f.Position.Longitude * 1e5)),
+		LatitudeE5:           int32(math.Round(f.Position.Latitude * 1e5)),
+		Altitude:             int32(math.Round(f.Position.Altitude)),
+		BearingD10:           degreesD10(f.Bearing),
+		Speed:                uint32(math.Round(math.Max(0, f.Speed))),
+		VerticalSpeed:        int32(math.Round(f.VerticalSpeed)),
+		CruiseAltitude:       int32(math.Round(f.CruiseAltitude)),
+		ProgressE4:           uint32(math.Round(f.Progress * 1e4)),
+		DistanceRemainingD10: uint32(math.Round(math.Max(0, f.DistanceRemaining) * 10)),
+		ScheduledDeparture:   unixSeconds(f.ScheduledDeparture),
+		ScheduledArrival:     unixSeconds(f.ScheduledArrival),
+		EstimatedArrival:     unixSeconds(f.EstimatedArrival),
+		LastComputedAt:       unixSeconds(f.LastComputedAt),
+		TraceId:              trace,
+		HeadingD10:           degreesD10(f.Heading),
+		GroundSpeed:          uint32(math.Round(math.Max(0, f.GroundSpeed))),
+	}
+}
+
+// degreesD10 quantizes an angle to tenths of a degree in [0, 3600). Bearings
+// come from orb in (-180, 180], so they are normalized first.
+func degreesD10(deg float64) uint32 {
+	return uint32(math.Round(math.Mod(math.Mod(deg, 360)+360, 360)*10)) % 3600
+}
+
+func unixSeconds(rfc3339 string) int64 {
+	t, err := time.Parse(time.RFC3339, rfc3339)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
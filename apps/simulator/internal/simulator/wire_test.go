@@ -0,0 +1,37 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/hannan/voyager/simulator/api/flightsv1"
+	"github.com/hannan/voyager/simulator/internal/flight"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWireBearingRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		bearing float64
+		want    uint32
+	}{
+		{0, 0},
+		{90, 900},
+		{180, 1800},
+		{-90, 2700},
+		{-0.04, 0},
+		{-179.9, 1801},
+		{359.96, 0},
+	} {
+		f := &flight.State{ID: "T1", AircraftType: "B738", Bearing: tc.bearing, Heading: tc.bearing}
+		var frame flightsv1.FlightsFrame
+		if err := proto.Unmarshal(encodeFlightsFrame(1, 0, 0, 1, []*flight.State{f}, viewport{}), &frame); err != nil {
+			t.Fatal(err)
+		}
+		if len(frame.Flights) != 1 {
+			t.Fatalf("bearing %g: got %d flights, want 1", tc.bearing, len(frame.Flights))
+		}
+		got := frame.Flights[0]
+		if got.BearingD10 != tc.want || got.HeadingD10 != tc.want {
+			t.Errorf("bearing %g: encoded bearing %d heading %d, want %d", tc.bearing, got.BearingD10, got.HeadingD10, tc.want)
+		}
+	}
+}
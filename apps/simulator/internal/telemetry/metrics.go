@@ -15,11 +15,14 @@ import (
 )
 
 var (
-	ActiveFlightsGauge    metric.Int64ObservableGauge
-	WebSocketConnections  metric.Int64UpDownCounter
-	ProcessMemoryGauge    metric.Int64ObservableGauge
-	ProcessCPUTimeCounter metric.Float64ObservableCounter
-	GoRoutinesGauge       metric.Int64ObservableGauge
+	ActiveFlightsGauge     metric.Int64ObservableGauge
+	WebSocketConnections   metric.Int64UpDownCounter
+	WebSocketDroppedFrames metric.Int64Counter
+	SeparationConflicts    metric.Int64Counter
+	RecordingDroppedFrames metric.Int64Counter
+	ProcessMemoryGauge     metric.Int64ObservableGauge
+	ProcessCPUTimeCounter  metric.Float64ObservableCounter
+	GoRoutinesGauge        metric.Int64ObservableGauge
 )
 
 func InitMetrics(serviceName string, flightCountFunc func() int) func() {
@@ -70,6 +73,30 @@ func InitMetrics(serviceName string, flightCountFunc func() int) func() {
 		log.Printf("Failed to create websocket_connections counter: %v", err)
 	}
 
+	WebSocketDroppedFrames, err = meter.Int64Counter(
+		"websocket_dropped_frames",
+		metric.WithDescription("Frames dropped because a WebSocket client fell behind"),
+	)
+	if err != nil {
+		log.Printf("Failed to create websocket_dropped_frames counter: %v", err)
+	}
+
+	SeparationConflicts, err = meter.Int64Counter(
+		"separation_conflicts",
+		metric.WithDescription("Pairs of flights found losing, or about to lose, separation"),
+	)
+	if err != nil {
+		log.Printf("Failed to create separation_conflicts counter: %v", err)
+	}
+
+	RecordingDroppedFrames, err = meter.Int64Counter(
+		"recording_dropped_frames",
+		metric.WithDescription("Frames left out of the recording because writing it fell behind"),
+	)
+	if err != nil {
+		log.Printf("Failed to create recording_dropped_frames counter: %v", err)
+	}
+
 	ProcessMemoryGauge, err = meter.Int64ObservableGauge(
 		"process_resident_memory_bytes",
 		metric.WithDescription("Resident memory size in bytes"),
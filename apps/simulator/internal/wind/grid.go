@@ -0,0 +1,134 @@
+package wind
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// Grid is wind sampled on a regular lon/lat grid at a few altitudes, read
+// from a JSON file:
+//
+//	{"west": -180, "south": -90, "step": 10, "levels": [10000, 30000, 39000],
+//	 "u": [[[...], ...], ...], "v": [[[...], ...], ...]}
+//
+// u and v are indexed [level][row][col], rows from south to north and
+// columns from west to east, in knots. Between samples the wind is
+// interpolated linearly; outside the grid it is held at the nearest edge,
+// except that a grid spanning 360° wraps around in longitude.
+type Grid struct {
+	West   float64       `json:"west"`
+	South  float64       `json:"south"`
+	Step   float64       `json:"step"`
+	Levels []float64     `json:"levels"`
+	U      [][][]float64 `json:"u"`
+	V      [][][]float64 `json:"v"`
+}
+
+// LoadGrid reads and checks a gridded wind file.
+func LoadGrid(path string) (*Grid, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var g Grid
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return nil, fmt.Errorf("parse wind grid %s: %w", path, err)
+	}
+	if err := g.validate(); err != nil {
+		return nil, fmt.Errorf("wind grid %s: %w", path, err)
+	}
+	return &g, nil
+}
+
+func (g *Grid) validate() error {
+	if !(g.Step > 0) {
+		return fmt.Errorf("step must be positive")
+	}
+	if len(g.Levels) == 0 {
+		return fmt.Errorf("no levels")
+	}
+	if !sort.Float64sAreSorted(g.Levels) {
+		return fmt.Errorf("levels must be in ascending order")
+	}
+	if len(g.U) != len(g.Levels) || len(g.V) != len(g.Levels) {
+		return fmt.Errorf("u and v need one layer per level")
+	}
+	rows := len(g.U[0])
+	if rows == 0 || len(g.U[0][0]) == 0 {
+		return fmt.Errorf("empty layer")
+	}
+	cols := len(g.U[0][0])
+	for _, layers := range [][][][]float64{g.U, g.V} {
+		for _, layer := range layers {
+			if len(layer) != rows {
+				return fmt.Errorf("every layer needs %d rows", rows)
+			}
+			for _, row := range layer {
+				if len(row) != cols {
+					return fmt.Errorf("every row needs %d columns", cols)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Grid) At(lon, lat, altitude float64) (float64, float64) {
+	i, fi := g.level(altitude)
+	u := lerp(g.sample(g.U[i], lon, lat), g.sample(g.U[min(i+1, len(g.Levels)-1)], lon, lat), fi)
+	v := lerp(g.sample(g.V[i], lon, lat), g.sample(g.V[min(i+1, len(g.Levels)-1)], lon, lat), fi)
+	return u, v
+}
+
+// level returns the layer at or below altitude and how far it is towards
+// the next one.
+func (g *Grid) level(altitude float64) (int, float64) {
+	n := len(g.Levels)
+	if altitude <= g.Levels[0] || n == 1 {
+		return 0, 0
+	}
+	if altitude >= g.Levels[n-1] {
+		return n - 1, 0
+	}
+	i := sort.SearchFloat64s(g.Levels, altitude) - 1
+	return i, (altitude - g.Levels[i]) / (g.Levels[i+1] - g.Levels[i])
+}
+
+// sample interpolates one layer bilinearly.
+func (g *Grid) sample(layer [][]float64, lon, lat float64) float64 {
+	rows, cols := len(layer), len(layer[0])
+	wraps := float64(cols)*g.Step >= 360
+
+	x := (lon - g.West) / g.Step
+	if wraps {
+		x = math.Mod(x, 360/g.Step)
+		if x < 0 {
+			x += 360 / g.Step
+		}
+	} else {
+		x = math.Max(0, math.Min(float64(cols-1), x))
+	}
+	y := math.Max(0, math.Min(float64(rows-1), (lat-g.South)/g.Step))
+
+	c0, r0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(c0), y-float64(r0)
+	c0 = min(c0, cols-1)
+	c1, r1 := c0+1, min(r0+1, rows-1)
+	if c1 >= cols {
+		if wraps {
+			c1 = 0
+		} else {
+			c1 = cols - 1
+		}
+	}
+	south := lerp(layer[r0][c0], layer[r0][c1], fx)
+	north := lerp(layer[r1][c0], layer[r1][c1], fx)
+	return lerp(south, north, fy)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
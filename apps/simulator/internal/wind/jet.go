@@ -0,0 +1,52 @@
+package wind
+
+import "math"
+
+// Jet is a band of zonal wind, strongest along its axis at Latitude and
+// Altitude and falling off as a Gaussian of the given widths. The axis
+// meanders north and south by Amplitude degrees, Waves times around the
+// globe, and the wind follows it. A negative Speed blows from the east.
+type Jet struct {
+	Latitude      float64 // degrees
+	Altitude      float64 // ft
+	LatitudeWidth float64 // degrees
+	AltitudeDepth float64 // ft
+	Speed         float64 // kt
+	Amplitude     float64 // degrees
+	Waves         float64
+}
+
+// JetStreams adds up its bands.
+type JetStreams []Jet
+
+// DefaultJetStreams has a polar and a subtropical jet in each hemisphere,
+// faster in the north, over trade winds near the surface in the tropics.
+var DefaultJetStreams = JetStreams{
+	{Latitude: 50, Altitude: 33000, LatitudeWidth: 6, AltitudeDepth: 8000, Speed: 120, Amplitude: 8, Waves: 4},
+	{Latitude: 30, Altitude: 39000, LatitudeWidth: 4, AltitudeDepth: 7000, Speed: 90, Amplitude: 3, Waves: 3},
+	{Latitude: -55, Altitude: 33000, LatitudeWidth: 6, AltitudeDepth: 8000, Speed: 100, Amplitude: 6, Waves: 4},
+	{Latitude: -30, Altitude: 39000, LatitudeWidth: 4, AltitudeDepth: 7000, Speed: 80, Amplitude: 3, Waves: 3},
+	{Latitude: 12, Altitude: 3000, LatitudeWidth: 8, AltitudeDepth: 8000, Speed: -15},
+	{Latitude: -12, Altitude: 3000, LatitudeWidth: 8, AltitudeDepth: 8000, Speed: -15},
+}
+
+func (j JetStreams) At(lon, lat, altitude float64) (float64, float64) {
+	var u, v float64
+	for _, jet := range j {
+		ju, jv := jet.at(lon, lat, altitude)
+		u, v = u+ju, v+jv
+	}
+	return u, v
+}
+
+func (j Jet) at(lon, lat, altitude float64) (float64, float64) {
+	phase := j.Waves * lon * math.Pi / 180
+	axis := j.Latitude + j.Amplitude*math.Sin(phase)
+	dLat, dAlt := (lat-axis)/j.LatitudeWidth, (altitude-j.Altitude)/j.AltitudeDepth
+	speed := j.Speed * math.Exp(-(dLat*dLat+dAlt*dAlt)/2)
+	// Slope of the axis in degrees of latitude per degree of longitude,
+	// stretched to local distances
+	slope := j.Amplitude * j.Waves * math.Pi / 180 * math.Cos(phase) / math.Max(math.Cos(lat*math.Pi/180), 0.1)
+	angle := math.Atan(slope)
+	return speed * math.Cos(angle), speed * math.Sin(angle)
+}
@@ -0,0 +1,52 @@
+package wind
+
+import (
+	"math"
+	"strings"
+)
+
+// Field is the wind at a point, in real knots towards the east (u) and
+// north (v). Altitude is in feet.
+type Field interface {
+	At(lon, lat, altitude float64) (u, v float64)
+}
+
+// Calm is a field with no wind anywhere.
+type Calm struct{}
+
+func (Calm) At(lon, lat, altitude float64) (float64, float64) {
+	return 0, 0
+}
+
+// Load returns the field named by spec: "calm", "jetstream" for
+// DefaultJetStreams, or the path of a gridded wind file.
+func Load(spec string) (Field, error) {
+	switch strings.ToLower(spec) {
+	case "", "calm":
+		return Calm{}, nil
+	case "jetstream":
+		return DefaultJetStreams, nil
+	}
+	return LoadGrid(spec)
+}
+
+// minGroundSpeedRatio keeps a flight moving, as a share of its airspeed,
+// when the wind would otherwise hold it still or push it backwards.
+const minGroundSpeedRatio = 0.1
+
+// Triangle solves the wind triangle: the heading to fly and the resulting
+// ground speed for a flight keeping to track (degrees) at airspeed tas in
+// wind u, v. Speeds share a unit.
+func Triangle(track, tas, u, v float64) (heading, groundSpeed float64) {
+	if tas <= 0 {
+		return track, 0
+	}
+	rad := track * math.Pi / 180
+	along := u*math.Sin(rad) + v*math.Cos(rad)
+	// Crosswind pushing the flight right of its track; heading left counters it
+	cross := u*math.Cos(rad) - v*math.Sin(rad)
+	correction := math.Asin(math.Max(-1, math.Min(1, -cross/tas)))
+	heading = math.Mod(track+correction*180/math.Pi+360, 360)
+	groundSpeed = math.Max(tas*math.Cos(correction)+along, tas*minGroundSpeedRatio)
+	return heading, groundSpeed
+}